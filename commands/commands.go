@@ -1 +1,134 @@
 package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var Names = []string{"exit", "commands", "look", "approach", "talk", "ask", "give", "leave", "inventory", "examine", "take", "drop", "use", "drink", "read", "push", "wake", "pet", "switch", "switch-on", "insert", "combine", "wait", "move", "map", "score", "hint", "achievements", "alias", "unalias", "aliases", "bye", "debug"}
+
+var Meta = []string{"commands", "inventory", "map", "score", "hint", "achievements", "aliases", "debug"}
+
+func IsMeta(input string) bool {
+	words := strings.Fields(input)
+	if len(words) == 0 {
+		return false
+	}
+	for _, command := range Meta {
+		if command == words[0] {
+			return true
+		}
+	}
+	return false
+}
+
+func IsBuiltin(name string) bool {
+	for _, command := range Names {
+		if command == name {
+			return true
+		}
+	}
+	return false
+}
+
+func Split(input string) []string {
+	input = strings.NewReplacer(",", " , ", ";", " ; ").Replace(input)
+	clauses := []string{}
+	clause := []string{}
+	for _, word := range strings.Fields(input) {
+		switch word {
+		case ",", ";", "then", "and":
+			if len(clause) > 0 {
+				clauses = append(clauses, strings.Join(clause, " "))
+				clause = nil
+			}
+		default:
+			clause = append(clause, word)
+		}
+	}
+	if len(clause) > 0 {
+		clauses = append(clauses, strings.Join(clause, " "))
+	}
+	return clauses
+}
+
+func PruneAliases(aliases map[string]string) []string {
+	pruned := []string{}
+	for name := range aliases {
+		if IsBuiltin(name) {
+			delete(aliases, name)
+			pruned = append(pruned, name)
+		}
+	}
+	sort.Strings(pruned)
+	return pruned
+}
+
+func ParseAlias(input string) (string, string, error) {
+	definition := strings.TrimSpace(strings.TrimPrefix(input, "alias"))
+	name, body, found := strings.Cut(definition, "=")
+	name = strings.TrimSpace(name)
+	body = strings.TrimSpace(body)
+	if !found || name == "" || body == "" {
+		return "", "", fmt.Errorf("usage: alias <name> = <commands>")
+	}
+	if strings.ContainsAny(name, " ,;") {
+		return "", "", fmt.Errorf("an alias name must be a single word")
+	}
+	return name, body, nil
+}
+
+func DefineAlias(aliases map[string]string, name string, body string) error {
+	if IsBuiltin(name) {
+		return fmt.Errorf("%s is already a command", name)
+	}
+	if refersTo(name, body, aliases, map[string]bool{}) {
+		return fmt.Errorf("%s cannot refer to itself", name)
+	}
+	aliases[name] = body
+	return nil
+}
+
+func Expand(input string, aliases map[string]string) ([]string, error) {
+	return expand(input, aliases, map[string]bool{})
+}
+
+func expand(input string, aliases map[string]string, expanding map[string]bool) ([]string, error) {
+	clauses := []string{}
+	for _, clause := range Split(input) {
+		words := strings.Fields(clause)
+		body, ok := aliases[words[0]]
+		if !ok {
+			clauses = append(clauses, clause)
+			continue
+		}
+		if expanding[words[0]] {
+			return nil, fmt.Errorf("alias %s refers to itself", words[0])
+		}
+		expanding[words[0]] = true
+		expanded, err := expand(strings.Join(append([]string{body}, words[1:]...), " "), aliases, expanding)
+		delete(expanding, words[0])
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, expanded...)
+	}
+	return clauses, nil
+}
+
+func refersTo(name string, body string, aliases map[string]string, visited map[string]bool) bool {
+	for _, clause := range Split(body) {
+		word := strings.Fields(clause)[0]
+		if word == name {
+			return true
+		}
+		if next, ok := aliases[word]; ok && !visited[word] {
+			visited[word] = true
+			if refersTo(name, next, aliases, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -3,8 +3,20 @@ package describable
 type Describable interface {
 	SetDescription(description string)
 	GetDescription() string
+	GetShortDescription() string
+	GetLongDescription() string
+}
+
+type Stateful interface {
+	Describable
+	SetState(state string)
+	GetState() string
 }
 
 func UpdateDescription(d Describable, newDescription string) {
 	d.SetDescription(newDescription)
 }
+
+func ChangeState(s Stateful, state string) {
+	s.SetState(state)
+}
@@ -0,0 +1,27 @@
+package entities
+
+import "fmt"
+
+type Achievement struct {
+	ID          string
+	Title       string
+	Description string
+	Event       string
+	Condition   func() bool
+}
+
+func TrackAchievements(bus *Bus, achievements []*Achievement, unlock func(*Achievement) bool) {
+	bus.Subscribe(EventTriggered, func(happening Happening) {
+		for _, achievement := range achievements {
+			if achievement.Event != happening.Event.ID {
+				continue
+			}
+			if achievement.Condition != nil && !achievement.Condition() {
+				continue
+			}
+			if unlock(achievement) {
+				fmt.Printf("Achievement unlocked: %s - %s\n", achievement.Title, achievement.Description)
+			}
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package entities
+
+import "fmt"
+
+type ItemAction struct {
+	Message       string
+	RequiresState string
+	UsesCharge    bool
+	Event         *Event
+}
+
+func (p *Player) Act(verb string, itemName string) bool {
+	item, ok := p.FindInventoryItem(itemName)
+	if !ok {
+		fmt.Printf("You don't have %s.\n", itemName)
+		return false
+	}
+	action, ok := item.Actions[verb]
+	if !ok {
+		fmt.Printf("You can't %s %s.\n", verb, item.Name)
+		return false
+	}
+	if action.RequiresState != "" && item.State != action.RequiresState {
+		fmt.Printf("You can't %s %s while it is %s.\n", verb, item.Name, item.State)
+		return false
+	}
+	if action.UsesCharge && item.Charges <= 0 {
+		fmt.Printf("There is nothing left of %s to %s.\n", item.Name, verb)
+		return false
+	}
+	fmt.Println(action.Message)
+	if action.Event != nil {
+		p.TriggerEvent(action.Event)
+	}
+	if action.UsesCharge {
+		item.Charges--
+		if item.Charges == 0 {
+			if item.SpentState != "" {
+				item.SetState(item.SpentState)
+			} else {
+				p.ChangeCarriedWeight(item, "decrease")
+				delete(p.Inventory, item.Name)
+				fmt.Printf("You have used up %s.\n", item.Name)
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package entities
+
+type HappeningKind int
+
+const (
+	ItemTaken HappeningKind = iota
+	ItemDropped
+	RoomEntered
+	EntityApproached
+	EventTriggered
+	GameEnded
+)
+
+type Happening struct {
+	Kind   HappeningKind
+	Item   *Item
+	Room   *Room
+	Entity *Entity
+	Event  *Event
+}
+
+type Bus struct {
+	subscribers map[HappeningKind][]func(Happening)
+}
+
+var Happenings = NewBus()
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[HappeningKind][]func(Happening))}
+}
+
+func (b *Bus) Subscribe(kind HappeningKind, handler func(Happening)) {
+	b.subscribers[kind] = append(b.subscribers[kind], handler)
+}
+
+func (b *Bus) Publish(happening Happening) {
+	for _, handler := range b.subscribers[happening.Kind] {
+		handler(happening)
+	}
+}
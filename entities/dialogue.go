@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Topic struct {
+	Keyword  string
+	Prompt   string
+	Response string
+	Requires *Event
+	Mood     string
+}
+
+func (e *Entity) AvailableTopics() []*Topic {
+	topics := []*Topic{}
+	for _, topic := range e.Topics {
+		if (topic.Requires == nil || topic.Requires.Triggered) && (topic.Mood == "" || topic.Mood == e.MoodLabel()) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+func (p *Player) Talk(entityName string) bool {
+	entity, ok := p.CurrentRoom.FindEntity(entityName)
+	if !ok || entity.Hidden {
+		fmt.Printf("You can't talk to %s.\n", entityName)
+		return false
+	}
+	if len(entity.AvailableTopics()) == 0 {
+		fmt.Printf("%s has nothing to say.\n", entity.Name)
+		return false
+	}
+	p.CurrentEntity = entity
+	p.ShowTopics()
+	return true
+}
+
+func (p *Player) ShowTopics() {
+	fmt.Printf("You talk to %s.\n\n", p.CurrentEntity.Name)
+	for i, topic := range p.CurrentEntity.AvailableTopics() {
+		fmt.Printf("%d. %s\n", i+1, topic.Prompt)
+	}
+	fmt.Println("\nType a number to choose, or 'bye' to stop talking.")
+}
+
+func (p *Player) ChooseTopic(choice string) bool {
+	topics := p.CurrentEntity.AvailableTopics()
+	number, err := strconv.Atoi(choice)
+	if err != nil || number < 1 || number > len(topics) {
+		fmt.Printf("Choose a number between 1 and %d.\n", len(topics))
+		return false
+	}
+	fmt.Printf("%s\n\n", topics[number-1].Response)
+	p.ShowTopics()
+	return true
+}
+
+func (p *Player) Ask(entityName string, keyword string) bool {
+	entity, ok := p.CurrentRoom.FindEntity(entityName)
+	if !ok || entity.Hidden {
+		fmt.Printf("You can't ask %s anything.\n", entityName)
+		return false
+	}
+	p.CurrentEntity = entity
+	for _, topic := range entity.AvailableTopics() {
+		if topic.Keyword == keyword {
+			fmt.Println(topic.Response)
+			return true
+		}
+	}
+	fmt.Printf("%s has nothing to say about %s.\n", entity.Name, keyword)
+	return false
+}
+
+func (p *Player) Give(itemName string, entityName string) bool {
+	entity, ok := p.CurrentRoom.FindEntity(entityName)
+	if !ok || entity.Hidden {
+		fmt.Printf("You can't give anything to %s.\n", entityName)
+		return false
+	}
+	p.CurrentEntity = entity
+	return p.Use(itemName, entity.Name)
+}
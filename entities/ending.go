@@ -0,0 +1,39 @@
+package entities
+
+import "academy-adventure-game/globalGame"
+
+type Ending struct {
+	ID      string
+	Title   string
+	Win     bool
+	Event   string
+	Summary string
+}
+
+type Endings struct {
+	list    []*Ending
+	byEvent map[string]*Ending
+	Reached *Ending
+}
+
+func NewEndings(endings ...*Ending) *Endings {
+	e := &Endings{byEvent: make(map[string]*Ending)}
+	for _, ending := range endings {
+		e.list = append(e.list, ending)
+		e.byEvent[ending.Event] = ending
+	}
+	return e
+}
+
+func (e *Endings) All() []*Ending {
+	return e.list
+}
+
+func (e *Endings) Track(bus *Bus) {
+	bus.Subscribe(EventTriggered, func(happening Happening) {
+		if ending, ok := e.byEvent[happening.Event.ID]; ok && e.Reached == nil {
+			e.Reached = ending
+			globalGame.GameOver = true
+		}
+	})
+}
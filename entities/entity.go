@@ -1,9 +1,37 @@
 package entities
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 type Entity struct {
-	Name        string
-	Description string
-	Hidden      bool
+	Name              string
+	Description       string
+	ShortDescription  string
+	LongDescription   string
+	State             string
+	StateDescriptions map[string]string
+	Hidden            bool
+	Aliases           []string
+	Topics            []*Topic
+	Inventory         map[string]*Item
+	Reactions         map[string]string
+	Watchful          bool
+	Asleep            bool
+	DistractedFor     int
+	Mood              *Mood
+	MoodDescriptions  map[string]string
+	Process           *Process
+	Machine           *StateMachine
+}
+
+func (e *Entity) DisplayName() string {
+	if e.Name == "" {
+		return ""
+	}
+	return strings.ToUpper(e.Name[:1]) + e.Name[1:]
 }
 
 func (e *Entity) SetDescription(description string) {
@@ -11,7 +39,84 @@ func (e *Entity) SetDescription(description string) {
 }
 
 func (e *Entity) GetDescription() string {
-	return e.Description
+	if state, ok := e.machineState(); ok && state.Description != "" {
+		return Render(state.Description)
+	}
+	return Render(e.Description)
+}
+
+func (e *Entity) GetShortDescription() string {
+	return Render(e.ShortDescription)
+}
+
+func (e *Entity) GetLongDescription() string {
+	if moodDescription, ok := e.MoodDescriptions[e.MoodLabel()]; ok {
+		return Render(moodDescription)
+	}
+	if state, ok := e.machineState(); ok && state.LongDescription != "" {
+		return Render(state.LongDescription)
+	}
+	return longDescription(e.State, e.StateDescriptions, e.LongDescription, e.Description)
+}
+
+func (e *Entity) SetState(state string) {
+	e.State = state
+}
+
+func (e *Entity) GetState() string {
+	return e.State
+}
+
+func (e *Entity) Matches(name string) bool {
+	return e.Name == name || hasAlias(e.Aliases, name)
+}
+
+func (e *Entity) Notices() bool {
+	return e.Watchful && !e.Asleep && e.DistractedFor == 0
+}
+
+func (e *Entity) Distract(turns int) {
+	e.DistractedFor = turns
+}
+
+func (e *Entity) PassTurn() {
+	if e.DistractedFor > 0 {
+		e.DistractedFor--
+	}
+}
+
+func (e *Entity) listing() string {
+	listing := e.Name
+	if short := e.GetShortDescription(); short != "" {
+		listing = fmt.Sprintf("%s: %s", e.Name, short)
+	}
+	if len(e.Inventory) != 0 {
+		listing = fmt.Sprintf("%s (holding %s)", listing, strings.Join(e.HeldItemNames(), ", "))
+	}
+	return listing
+}
+
+func (e *Entity) HeldItemNames() []string {
+	names := []string{}
+	for name := range e.Inventory {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *Player) Witness() *Entity {
+	names := []string{}
+	for name := range p.CurrentRoom.Entities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if entity := p.CurrentRoom.Entities[name]; !entity.Hidden && entity.Notices() {
+			return entity
+		}
+	}
+	return nil
 }
 
 func (p *Player) EntitiesArePresent() bool {
@@ -1,7 +1,20 @@
 package entities
 
 type Event struct {
-	Description string
-	Outcome     string
-	Triggered   bool
+	ID        string
+	Outcome   string
+	Triggered bool
+}
+
+type TimedEvent struct {
+	At    int
+	Event *Event
+}
+
+func (p *Player) TriggerTimedEvents(timedEvents []*TimedEvent, now int) {
+	for _, timedEvent := range timedEvents {
+		if !timedEvent.Event.Triggered && now >= timedEvent.At {
+			p.TriggerEvent(timedEvent.Event)
+		}
+	}
 }
@@ -0,0 +1,48 @@
+package entities
+
+type HintStage struct {
+	ID      string
+	Applies func() bool
+	Tiers   []string
+}
+
+type Hints struct {
+	Stages []*HintStage
+	Budget int
+	Used   int
+	given  map[string]int
+}
+
+func NewHints(budget int, stages ...*HintStage) *Hints {
+	return &Hints{Stages: stages, Budget: budget, given: make(map[string]int)}
+}
+
+func (h *Hints) Remaining() int {
+	return h.Budget - h.Used
+}
+
+func (h *Hints) Current() (*HintStage, bool) {
+	for _, stage := range h.Stages {
+		if stage.Applies() {
+			return stage, true
+		}
+	}
+	return nil, false
+}
+
+func (h *Hints) Next() (string, bool) {
+	if h.Remaining() <= 0 {
+		return "", false
+	}
+	stage, ok := h.Current()
+	if !ok || len(stage.Tiers) == 0 {
+		return "", false
+	}
+	tier := h.given[stage.ID]
+	if tier >= len(stage.Tiers) {
+		tier = len(stage.Tiers) - 1
+	}
+	h.given[stage.ID]++
+	h.Used++
+	return stage.Tiers[tier], true
+}
@@ -1,49 +1,102 @@
 package entities
 
+import "academy-adventure-game/globalGame"
+
 type Interaction struct {
+	Verb       string
 	ItemName   string
 	EntityName string
 	Event      *Event
+	Reward     string
+	Mood       int
+	ItemState  string
+	Again      string
+	Requires   func() bool
+	Refusal    string
 }
 
-var ValidInteractions = []*Interaction{}
+func allPlatesLoaded() bool {
+	for _, plate := range globalGame.PlateOrder {
+		if !Index.HasTriggered(plate + "-loaded") {
+			return false
+		}
+	}
+	return true
+}
 
 func ValidInteraction() {
-	ValidInteractions = []*Interaction{
-		{
+	manifesto := "You read the manifesto slowly, word by word:\n\nIndividuals and Interactions over processes and tools.\nWorking Software over comprehensive documentation.\nCustomer Collaboration over contract negotiation.\nResponding To Change over following a plan.\n\nSome of the words are capitalised, as though they matter more than the rest.\n"
+	Index = NewRegistry(
+		&Interaction{
 			ItemName:   "tea",
 			EntityName: "rosie",
-			Event:      &Event{Description: "get-your-lanyard", Outcome: "Cheers! I needed that... by the way, where is your lanyard? I must have forgotten to give it to you.\nYou'll need that to move between rooms, here it is.\n\n(lanyard can now be found in the room).\n", Triggered: false},
+			Event:      &Event{ID: "get-your-lanyard", Outcome: "Cheers! I needed that... by the way, where is your lanyard? I must have forgotten to give it to you.\nYou'll need that to move between rooms, here it is.\n", Triggered: false},
+			Reward:     "lanyard",
+			Mood:       30,
+			ItemState:  "hot",
 		},
-		{
+		&Interaction{
+			ItemName:   "cd",
+			EntityName: "computer",
+			Event:      &Event{ID: "cd-inserted", Outcome: "The computer whirs as it reads the disc. A single folder appears: /secret-files, holding a file called unlock-exits-instructions.txt.\nThe file is locked to this machine, but the terminal in the next room should be able to open it.\n", Triggered: false},
+		},
+		&Interaction{
 			ItemName:   "first-plate",
 			EntityName: "dishwasher",
-			Event:      &Event{Description: "first-plate-loaded", Outcome: "You loaded the first plate into the dishwasher.", Triggered: false},
+			Event:      &Event{ID: "first-plate-loaded", Outcome: "You loaded the first plate into the dishwasher.", Triggered: false},
 		},
-		{
+		&Interaction{
 			ItemName:   "second-plate",
 			EntityName: "dishwasher",
-			Event:      &Event{Description: "second-plate-loaded", Outcome: "You loaded the second plate into the dishwasher.", Triggered: false},
+			Event:      &Event{ID: "second-plate-loaded", Outcome: "You loaded the second plate into the dishwasher.", Triggered: false},
 		},
-		{
+		&Interaction{
 			ItemName:   "third-plate",
 			EntityName: "dishwasher",
-			Event:      &Event{Description: "third-plate-loaded", Outcome: "You loaded the third plate into the dishwasher.", Triggered: false},
+			Event:      &Event{ID: "third-plate-loaded", Outcome: "You loaded the third plate into the dishwasher.", Triggered: false},
 		},
-		{
+		&Interaction{
 			ItemName:   "fourth-plate",
 			EntityName: "dishwasher",
-			Event:      &Event{Description: "fourth-plate-loaded", Outcome: "You loaded the fourth plate into the dishwasher.", Triggered: false},
+			Event:      &Event{ID: "fourth-plate-loaded", Outcome: "You loaded the fourth plate into the dishwasher.", Triggered: false},
 		},
-		{
+		&Interaction{
 			ItemName:   "fifth-plate",
 			EntityName: "dishwasher",
-			Event:      &Event{Description: "fifth-plate-loaded", Outcome: "You loaded the fifth plate into the dishwasher.", Triggered: false},
+			Event:      &Event{ID: "fifth-plate-loaded", Outcome: "You loaded the fifth plate into the dishwasher.", Triggered: false},
 		},
-		{
+		&Interaction{
 			ItemName:   "sixth-plate",
 			EntityName: "dishwasher",
-			Event:      &Event{Description: "sixth-plate-loaded", Outcome: "You loaded the sixth plate into the dishwasher.", Triggered: false},
+			Event:      &Event{ID: "sixth-plate-loaded", Outcome: "You loaded the sixth plate into the dishwasher.\nThat's the last of them. All that's left is to switch the dishwasher on.", Triggered: false},
 		},
-	}
+		&Interaction{
+			Verb:       "push",
+			EntityName: "desk",
+			Event:      &Event{ID: "desk-pushed", Outcome: "You give the desk a shove. The mess on it shifts.", Triggered: false},
+		},
+		&Interaction{
+			Verb:       "wake",
+			EntityName: "sofa",
+			Event:      &Event{ID: "student-woken", Outcome: "You nudge the sleeping student. They groan, sit up and blink at you.\n\"Is it 4pm yet? No? Wake me when it is.\" They stay sitting up, keeping a bleary eye on their things.\n", Triggered: false},
+		},
+		&Interaction{
+			Verb:       "pet",
+			EntityName: "cat",
+			Event:      &Event{ID: "cat-petted", Outcome: "The cat purrs and headbutts your hand. Its name tag jingles: 'unlock-exits-instructions.txt'.\n", Triggered: false},
+		},
+		&Interaction{
+			Verb:       "read",
+			EntityName: "agile-manifesto",
+			Event:      &Event{ID: "manifesto-read", Outcome: manifesto, Triggered: false},
+			Again:      manifesto,
+		},
+		&Interaction{
+			Verb:       "switch-on",
+			EntityName: "dishwasher",
+			Event:      &Event{ID: "dishwasher-switched-on", Outcome: "You press the dishwasher's power button.", Triggered: false},
+			Requires:   allPlatesLoaded,
+			Refusal:    "Nothing happens. There's no point running the dishwasher until all the plates are loaded.",
+		},
+	)
 }
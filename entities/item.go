@@ -1,10 +1,21 @@
 package entities
 
 type Item struct {
-	Name        string
-	Description string
-	Weight      int
-	Hidden      bool
+	Name              string
+	Description       string
+	ShortDescription  string
+	LongDescription   string
+	State             string
+	StateDescriptions map[string]string
+	Weight            int
+	Hidden            bool
+	Aliases           []string
+	Contraband        bool
+	CaughtEvent       *Event
+	CaughtPenalty     int
+	Charges           int
+	SpentState        string
+	Actions           map[string]*ItemAction
 }
 
 func (i *Item) SetDescription(description string) {
@@ -12,5 +23,47 @@ func (i *Item) SetDescription(description string) {
 }
 
 func (i *Item) GetDescription() string {
-	return i.Description
+	return Render(i.Description)
+}
+
+func (i *Item) GetShortDescription() string {
+	if i.ShortDescription != "" {
+		return Render(i.ShortDescription)
+	}
+	return Render(i.Description)
+}
+
+func (i *Item) GetLongDescription() string {
+	return longDescription(i.State, i.StateDescriptions, i.LongDescription, i.Description)
+}
+
+func (i *Item) SetState(state string) {
+	i.State = state
+}
+
+func (i *Item) GetState() string {
+	return i.State
+}
+
+func (i *Item) Matches(name string) bool {
+	return i.Name == name || hasAlias(i.Aliases, name)
+}
+
+func longDescription(state string, stateDescriptions map[string]string, long string, description string) string {
+	if stateDescription, ok := stateDescriptions[state]; ok {
+		return Render(stateDescription)
+	}
+	if long != "" {
+		return Render(long)
+	}
+	return Render(description)
+}
+
+func hasAlias(aliases []string, name string) bool {
+	for _, alias := range aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type MachineState struct {
+	Description     string
+	LongDescription string
+	OnEnter         func()
+}
+
+type StateMachine struct {
+	States      map[string]*MachineState
+	Transitions map[string][]string
+}
+
+func (m *StateMachine) Allows(from string, to string) bool {
+	for _, next := range m.Transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Entity) Transition(to string) error {
+	if e.Machine == nil {
+		return fmt.Errorf("%s has no state machine", e.Name)
+	}
+	if e.State == to {
+		return fmt.Errorf("%s is already %s", e.Name, to)
+	}
+	state, ok := e.Machine.States[to]
+	if !ok {
+		return fmt.Errorf("%s has no state called %s", e.Name, to)
+	}
+	if !e.Machine.Allows(e.State, to) {
+		return fmt.Errorf("%s cannot go from %s to %s", e.Name, e.State, to)
+	}
+	e.State = to
+	if state.OnEnter != nil {
+		state.OnEnter()
+	}
+	return nil
+}
+
+func (e *Entity) machineState() (*MachineState, bool) {
+	if e.Machine == nil {
+		return nil, false
+	}
+	state, ok := e.Machine.States[e.State]
+	return state, ok
+}
+
+func DescribeMachine(e *Entity) string {
+	if e.Machine == nil {
+		return fmt.Sprintf("%s: no state machine", e.Name)
+	}
+	next := append([]string{}, e.Machine.Transitions[e.State]...)
+	sort.Strings(next)
+	if len(next) == 0 {
+		return fmt.Sprintf("%s: %s (final)", e.Name, e.State)
+	}
+	return fmt.Sprintf("%s: %s (can go to %s)", e.Name, e.State, strings.Join(next, ", "))
+}
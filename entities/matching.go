@@ -0,0 +1,137 @@
+package entities
+
+import (
+	"sort"
+	"strings"
+)
+
+type Scope int
+
+const (
+	RoomItems Scope = 1 << iota
+	RoomEntities
+	InventoryItems
+)
+
+func (p *Player) Resolve(term string, scope Scope) (string, []string) {
+	objects := p.visibleObjects(scope)
+	candidates := []string{}
+	for name, aliases := range objects {
+		if nameMatches(term, name) || aliasMatches(term, aliases) {
+			candidates = append(candidates, name)
+		}
+	}
+	sortCandidates(term, candidates)
+	switch {
+	case len(candidates) == 0:
+		return term, nil
+	case len(candidates) == 1 && (strings.HasPrefix(candidates[0], term) || aliasHasPrefix(term, objects[candidates[0]])):
+		return candidates[0], nil
+	}
+	return "", candidates
+}
+
+func (p *Player) VisibleNames(scope Scope) []string {
+	names := []string{}
+	for name := range p.visibleObjects(scope) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *Player) visibleObjects(scope Scope) map[string][]string {
+	objects := make(map[string][]string)
+	if scope&RoomItems != 0 && p.CurrentRoom != nil {
+		for name, item := range p.CurrentRoom.Items {
+			if !item.Hidden {
+				objects[name] = item.Aliases
+			}
+		}
+	}
+	if scope&RoomEntities != 0 && p.CurrentRoom != nil {
+		for name, entity := range p.CurrentRoom.Entities {
+			if !entity.Hidden {
+				objects[name] = entity.Aliases
+			}
+		}
+	}
+	if scope&InventoryItems != 0 {
+		for name, item := range p.Inventory {
+			objects[name] = item.Aliases
+		}
+	}
+	return objects
+}
+
+func MatchCandidates(term string, names []string) []string {
+	matches := []string{}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] || !nameMatches(term, name) {
+			continue
+		}
+		seen[name] = true
+		matches = append(matches, name)
+	}
+	sortCandidates(term, matches)
+	return matches
+}
+
+func sortCandidates(term string, names []string) {
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == term) != (names[j] == term) {
+			return names[i] == term
+		}
+		return names[i] < names[j]
+	})
+}
+
+func ChooseCandidate(answer string, candidates []string) (string, bool) {
+	if len(candidates) == 1 && (answer == "yes" || answer == "y") {
+		return candidates[0], true
+	}
+	for _, candidate := range candidates {
+		if candidate == answer {
+			return candidate, true
+		}
+	}
+	matches := MatchCandidates(answer, candidates)
+	if len(matches) == 1 {
+		return matches[0], true
+	}
+	return "", false
+}
+
+func aliasMatches(term string, aliases []string) bool {
+	for _, alias := range aliases {
+		if nameMatches(term, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+func aliasHasPrefix(term string, aliases []string) bool {
+	for _, alias := range aliases {
+		if strings.HasPrefix(alias, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func nameMatches(term string, name string) bool {
+	if term == "" {
+		return false
+	}
+	if strings.HasPrefix(name, term) {
+		return true
+	}
+	for _, part := range strings.Split(name, "-") {
+		if strings.HasPrefix(part, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package entities
+
+import "fmt"
+
+type Mood struct {
+	Value      int
+	GrumpyAt   int
+	AnnoyedAt  int
+	CheerfulAt int
+}
+
+func (m *Mood) Label() string {
+	switch {
+	case m.Value <= m.GrumpyAt:
+		return "grumpy"
+	case m.Value <= m.AnnoyedAt:
+		return "annoyed"
+	case m.Value >= m.CheerfulAt:
+		return "cheerful"
+	}
+	return "calm"
+}
+
+func (e *Entity) MoodLabel() string {
+	if e.Mood == nil {
+		return ""
+	}
+	return e.Mood.Label()
+}
+
+func (e *Entity) IsGrumpy() bool {
+	return e.MoodLabel() == "grumpy"
+}
+
+func (e *Entity) ChangeMood(delta int) {
+	if e.Mood == nil || delta == 0 {
+		return
+	}
+	e.Mood.Value += delta
+	if delta > 0 {
+		fmt.Printf("%s looks pleased with you.\n", e.DisplayName())
+	} else {
+		fmt.Printf("%s looks annoyed with you.\n", e.DisplayName())
+	}
+}
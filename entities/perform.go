@@ -0,0 +1,41 @@
+package entities
+
+import "fmt"
+
+func (p *Player) Perform(verb string, entityName string) bool {
+	entity, ok := p.CurrentRoom.FindEntity(entityName)
+	if !ok || entity.Hidden {
+		fmt.Printf("You can't %s %s.\n", verb, entityName)
+		return false
+	}
+	interaction, ok := Index.FindVerb(verb, entity.Name)
+	if !ok {
+		fmt.Printf("You can't %s %s.\n", verb, entity.Name)
+		return false
+	}
+	if interaction.ItemName != "" {
+		if _, ok := p.FindInventoryItem(interaction.ItemName); !ok {
+			fmt.Printf("You need %s to %s %s.\n", interaction.ItemName, verb, entity.Name)
+			return false
+		}
+	}
+	if interaction.Event.Triggered {
+		if interaction.Again != "" {
+			fmt.Println(interaction.Again)
+			return true
+		}
+		fmt.Printf("You %s %s again, but nothing more happens.\n", verb, entity.Name)
+		return false
+	}
+	if interaction.Requires != nil && !interaction.Requires() {
+		if interaction.Refusal != "" {
+			fmt.Println(interaction.Refusal)
+		} else {
+			fmt.Printf("Nothing happens when you %s %s.\n", verb, entity.Name)
+		}
+		return false
+	}
+	p.TriggerEvent(interaction.Event)
+	entity.ChangeMood(interaction.Mood)
+	return true
+}
@@ -3,8 +3,11 @@ package entities
 import (
 	"academy-adventure-game/globalGame"
 	"fmt"
+	"strings"
 )
 
+var PlatesSmashed = &Event{ID: "plates-smashed", Outcome: "As you attempt to grab the greasy plates without removing the ones stacked above them, they slip from your grasp and shatter, creating a chaotic mess.\n\nNow Rosie is very grumpy."}
+
 type Player struct {
 	CurrentRoom     *Room
 	Inventory       map[string]*Item
@@ -13,40 +16,43 @@ type Player struct {
 	AvailableWeight int
 }
 
-func (p *Player) Move(direction string) {
+func (p *Player) Move(direction string) bool {
 	if p.CurrentEntity != nil {
 		p.CurrentEntity = nil
 	}
-	if newRoom, ok := p.CurrentRoom.Exits[direction]; ok {
+	if newRoom, ok := p.CurrentRoom.FindExit(direction); ok {
 		p.CurrentRoom = newRoom
 
 		fmt.Printf("You are in %s\n", p.CurrentRoom.Name)
-	} else {
-		fmt.Println("You can't go that way!")
+		Happenings.Publish(Happening{Kind: RoomEntered, Room: newRoom})
+		return true
 	}
+	fmt.Println("You can't go that way!")
+	return false
 }
 
-func (p *Player) Take(itemName string) {
-	item, ok := p.CurrentRoom.Items[itemName]
+func (p *Player) Take(itemName string) bool {
+	item, ok := p.CurrentRoom.FindItem(itemName)
 	switch {
 	case !ok || item.Hidden:
 		fmt.Printf("You can't take %s\n", itemName)
-		return
+		return false
 	case p.AvailableWeight < item.Weight:
 		fmt.Println("Weight limit reached! Please drop an item before taking more.")
-		return
-	case globalGame.IsPlate(itemName):
-		if itemName == globalGame.PlateOrder[globalGame.CurrentPlateIndex] {
+		return false
+	case globalGame.IsPlate(item.Name):
+		if item.Name == globalGame.PlateOrder[globalGame.CurrentPlateIndex] {
 			p.Inventory[item.Name] = item
 			p.ChangeCarriedWeight(item, "increase")
 			delete(p.CurrentRoom.Items, item.Name)
 			globalGame.CurrentPlateIndex++
 
 			fmt.Printf("%s has been added to your inventory.\n", item.Name)
-		} else {
-			fmt.Println("As you attempt to grab the greasy plates without removing the ones stacked above them, they slip from your grasp and shatter, creating a chaotic mess.\n\nNow Rosie is very grumpy.")
-			globalGame.GameOver = true
+			Happenings.Publish(Happening{Kind: ItemTaken, Item: item, Room: p.CurrentRoom})
+			return true
 		}
+		p.TriggerEvent(PlatesSmashed)
+		return false
 
 	default:
 		p.Inventory[item.Name] = item
@@ -54,40 +60,80 @@ func (p *Player) Take(itemName string) {
 		delete(p.CurrentRoom.Items, item.Name)
 
 		fmt.Printf("%s has been added to your inventory.\n", item.Name)
+		Happenings.Publish(Happening{Kind: ItemTaken, Item: item, Room: p.CurrentRoom})
+		if witness := p.Witness(); item.Contraband && witness != nil {
+			if item.CaughtEvent != nil {
+				p.TriggerEvent(item.CaughtEvent)
+			}
+			witness.ChangeMood(-item.CaughtPenalty)
+		}
+		return true
 	}
 }
 
-func (p *Player) Use(itemName string, target string) {
+func (p *Player) Use(itemName string, target string) bool {
 	if p.CurrentEntity == nil {
 		fmt.Println("Approach to use an item.")
-		return
+		return false
 	}
-	if p.CurrentEntity.Name == target {
-		if _, ok := p.Inventory[itemName]; ok {
-			for _, interaction := range ValidInteractions {
-				if interaction.ItemName == itemName && interaction.EntityName == target {
-					p.TriggerEvent(interaction.Event)
-					p.ChangeCarriedWeight(p.Inventory[itemName], "decrease")
-					delete(p.Inventory, itemName)
-					return
+	if p.CurrentEntity.Matches(target) {
+		if item, ok := p.FindInventoryItem(itemName); ok {
+			if interaction, ok := Index.FindUse(item.Name, p.CurrentEntity.Name); ok {
+				if interaction.ItemState != "" && item.State != interaction.ItemState {
+					fmt.Printf("%s won't take %s while it is %s.\n", p.CurrentEntity.Name, item.Name, item.State)
+					return false
 				}
+				p.TriggerEvent(interaction.Event)
+				p.CurrentEntity.ChangeMood(interaction.Mood)
+				p.handOver(item)
+				if reward, ok := p.CurrentEntity.Inventory[interaction.Reward]; ok {
+					p.Receive(reward, p.CurrentEntity)
+				}
+				return true
+			}
+			if reaction, ok := p.CurrentEntity.Reactions[item.Name]; ok && p.CurrentEntity.Inventory != nil {
+				fmt.Println(reaction)
+				p.handOver(item)
+				return true
 			}
 		} else {
 			fmt.Printf("You don't have %s.\n", itemName)
-			return
+			return false
 		}
 	} else {
 		fmt.Printf("%s not found.\n", target)
-		return
+		return false
 	}
 	fmt.Printf("You can't use %s on %s.\n", itemName, target)
+	return false
+}
+
+func (p *Player) handOver(item *Item) {
+	p.ChangeCarriedWeight(item, "decrease")
+	delete(p.Inventory, item.Name)
+	if p.CurrentEntity.Inventory != nil {
+		p.CurrentEntity.Inventory[item.Name] = item
+	}
 }
 
-func (p *Player) Drop(itemName string) {
-	if item, ok := p.Inventory[itemName]; ok {
-		if globalGame.IsPlate(itemName) {
+func (p *Player) Receive(item *Item, from *Entity) {
+	delete(from.Inventory, item.Name)
+	item.Hidden = false
+	if p.AvailableWeight < item.Weight {
+		p.CurrentRoom.Items[item.Name] = item
+		fmt.Printf("%s hands you %s, but you can't carry it, so it ends up in the room.\n", from.Name, item.Name)
+		return
+	}
+	p.Inventory[item.Name] = item
+	p.ChangeCarriedWeight(item, "increase")
+	fmt.Printf("%s gives you %s.\n", from.Name, item.Name)
+}
+
+func (p *Player) Drop(itemName string) bool {
+	if item, ok := p.FindInventoryItem(itemName); ok {
+		if globalGame.IsPlate(item.Name) {
 			println("You can't just leave those plates lying around! It's time to load them into the dishwasher!")
-			return
+			return false
 		}
 
 		delete(p.Inventory, item.Name)
@@ -95,31 +141,68 @@ func (p *Player) Drop(itemName string) {
 		p.CurrentRoom.Items[item.Name] = item
 
 		fmt.Printf("You dropped %s.\n", item.Name)
-	} else {
-		fmt.Printf("You don't have %s.\n", itemName)
+		Happenings.Publish(Happening{Kind: ItemDropped, Item: item, Room: p.CurrentRoom})
+		return true
 	}
+	fmt.Printf("You don't have %s.\n", itemName)
+	return false
 }
 
-func (p *Player) Approach(entityName string) {
+func (p *Player) Approach(entityName string) bool {
 	if p.CurrentEntity != nil {
 		p.CurrentEntity = nil
 	}
-	if entity, ok := p.CurrentRoom.Entities[entityName]; ok && !entity.Hidden {
+	if entity, ok := p.CurrentRoom.FindEntity(entityName); ok && !entity.Hidden {
 
 		p.CurrentEntity = entity
-		fmt.Println(entity.Description)
-	} else {
-		fmt.Printf("You can't approach %s.\n", entityName)
+		fmt.Println(entity.GetDescription())
+		Happenings.Publish(Happening{Kind: EntityApproached, Entity: entity, Room: p.CurrentRoom})
+		return true
 	}
+	fmt.Printf("You can't approach %s.\n", entityName)
+	return false
 }
 
-func (p *Player) Leave() {
+func (p *Player) Leave() bool {
 	if p.CurrentEntity != nil {
 		p.CurrentEntity = nil
 		p.ShowRoom()
-	} else {
-		fmt.Println("You have not approached anything. If you wish to leave the game, use the exit command.")
+		return true
+	}
+	fmt.Println("You have not approached anything. If you wish to leave the game, use the exit command.")
+	return false
+}
+
+func (p *Player) Examine(name string) bool {
+	if item, ok := p.FindInventoryItem(name); ok {
+		fmt.Println(item.GetLongDescription())
+		return true
+	}
+	if item, ok := p.CurrentRoom.FindItem(name); ok && !item.Hidden {
+		fmt.Println(item.GetLongDescription())
+		return true
+	}
+	if entity, ok := p.CurrentRoom.FindEntity(name); ok && !entity.Hidden {
+		fmt.Println(entity.GetLongDescription())
+		if len(entity.Inventory) != 0 {
+			fmt.Printf("%s is holding: %s.\n", entity.Name, strings.Join(entity.HeldItemNames(), ", "))
+		}
+		return true
+	}
+	fmt.Printf("You can't examine %s.\n", name)
+	return false
+}
+
+func (p *Player) FindInventoryItem(name string) (*Item, bool) {
+	if item, ok := p.Inventory[name]; ok {
+		return item, true
+	}
+	for _, key := range sortedKeys(p.Inventory) {
+		if item := p.Inventory[key]; item.Matches(name) {
+			return item, true
+		}
 	}
+	return nil, false
 }
 
 func (p *Player) ShowInventory() {
@@ -129,7 +212,7 @@ func (p *Player) ShowInventory() {
 	}
 	fmt.Printf("Available space: %d\nYour inventory contains:\n", p.AvailableWeight)
 	for itemName, item := range p.Inventory {
-		fmt.Printf("- %s: %s Weight: %d\n", itemName, item.Description, item.Weight)
+		fmt.Printf("- %s: %s Weight: %d\n", itemName, item.GetShortDescription(), item.Weight)
 	}
 }
 
@@ -160,10 +243,11 @@ func (p *Player) ChangeCarriedWeight(item *Item, operation string) {
 func (p *Player) TriggerEvent(event *Event) {
 	fmt.Println(event.Outcome)
 	event.Triggered = true
+	Happenings.Publish(Happening{Kind: EventTriggered, Event: event, Entity: p.CurrentEntity})
 }
 
 func (p *Player) ShowRoom() {
-	fmt.Printf("You are in %s\n\n%s\n", p.CurrentRoom.Name, p.CurrentRoom.Description)
+	fmt.Printf("You are in %s\n\n%s\n", p.CurrentRoom.Name, p.CurrentRoom.GetDescription())
 
 	if p.EntitiesArePresent() {
 		fmt.Println("\nYou can approach:")
@@ -171,13 +255,13 @@ func (p *Player) ShowRoom() {
 			switch {
 			case p.CurrentEntity != nil:
 				if entity.Name == p.CurrentEntity.Name {
-					fmt.Printf("- %s (currently approached)\n", entity.Name)
+					fmt.Printf("- %s (currently approached)\n", entity.listing())
 				} else if !entity.Hidden {
-					fmt.Printf("- %s\n", entity.Name)
+					fmt.Printf("- %s\n", entity.listing())
 				}
 			default:
 				if !entity.Hidden {
-					fmt.Printf("- %s\n", entity.Name)
+					fmt.Printf("- %s\n", entity.listing())
 				}
 			}
 		}
@@ -187,7 +271,7 @@ func (p *Player) ShowRoom() {
 		fmt.Println("\nThe room contains:")
 		for itemName, item := range p.CurrentRoom.Items {
 			if !item.Hidden {
-				fmt.Printf("- %s: %s Weight: %d\n", itemName, item.Description, item.Weight)
+				fmt.Printf("- %s: %s Weight: %d\n", itemName, item.GetShortDescription(), item.Weight)
 			}
 		}
 	}
@@ -0,0 +1,49 @@
+package entities
+
+import "fmt"
+
+type Process struct {
+	Entity      *Entity
+	Turns       int
+	Remaining   int
+	Running     bool
+	Repeatable  bool
+	Completions int
+	Starting    string
+	Progress    string
+	Done        *Event
+}
+
+func (pr *Process) Start() bool {
+	if pr.Running || (pr.Done.Triggered && !pr.Repeatable) {
+		return false
+	}
+	pr.Running = true
+	pr.Remaining = pr.Turns
+	if pr.Starting != "" {
+		fmt.Println(pr.Starting)
+	}
+	return true
+}
+
+func (pr *Process) Tick(p *Player) {
+	if !pr.Running {
+		return
+	}
+	pr.Remaining--
+	if pr.Remaining > 0 {
+		if pr.Progress != "" && p.CurrentRoom.Entities[pr.Entity.Name] == pr.Entity {
+			fmt.Println(pr.Progress)
+		}
+		return
+	}
+	pr.Running = false
+	pr.Completions++
+	p.TriggerEvent(pr.Done)
+}
+
+func TickProcesses(processes []*Process, p *Player) {
+	for _, process := range processes {
+		process.Tick(p)
+	}
+}
@@ -0,0 +1,88 @@
+package entities
+
+import "fmt"
+
+type Ingredient struct {
+	Name     string
+	Consumed bool
+}
+
+type Recipe struct {
+	Inputs  []Ingredient
+	Output  *Item
+	Message string
+}
+
+var ValidRecipes = []*Recipe{}
+
+func ValidRecipe() {
+	ValidRecipes = []*Recipe{
+		{
+			Inputs: []Ingredient{{Name: "torn-note", Consumed: true}, {Name: "note-scrap", Consumed: true}},
+			Output: &Item{Name: "taped-note", Description: "A note, taped back together, in Alan's handwriting.", Weight: 1, LongDescription: "Two halves of a note taped back together. The handwriting is unmistakably Alan's.", Actions: map[string]*ItemAction{
+				"read": {Message: "'Note to self: the password is the words that matter most on the manifesto. First letters only. Do NOT forget it again. - A'"},
+			}},
+			Message: "You line up the torn edges and tape the two halves of the note back together.",
+		},
+	}
+}
+
+func (r *Recipe) Matches(first string, second string) bool {
+	if len(r.Inputs) != 2 {
+		return false
+	}
+	return (r.Inputs[0].Name == first && r.Inputs[1].Name == second) || (r.Inputs[0].Name == second && r.Inputs[1].Name == first)
+}
+
+func FindRecipe(first string, second string) (*Recipe, bool) {
+	for _, recipe := range ValidRecipes {
+		if recipe.Matches(first, second) {
+			return recipe, true
+		}
+	}
+	return nil, false
+}
+
+func (p *Player) Combine(firstName string, secondName string) bool {
+	first, ok := p.FindInventoryItem(firstName)
+	if !ok {
+		fmt.Printf("You don't have %s.\n", firstName)
+		return false
+	}
+	second, ok := p.FindInventoryItem(secondName)
+	if !ok {
+		fmt.Printf("You don't have %s.\n", secondName)
+		return false
+	}
+	if first == second {
+		fmt.Println("You can't combine something with itself.")
+		return false
+	}
+	recipe, ok := FindRecipe(first.Name, second.Name)
+	if !ok {
+		fmt.Printf("You can't combine %s with %s.\n", first.Name, second.Name)
+		return false
+	}
+	if _, held := p.Inventory[recipe.Output.Name]; held {
+		fmt.Printf("You already have %s.\n", recipe.Output.Name)
+		return false
+	}
+	for _, ingredient := range recipe.Inputs {
+		if ingredient.Consumed {
+			item := p.Inventory[ingredient.Name]
+			p.ChangeCarriedWeight(item, "decrease")
+			delete(p.Inventory, item.Name)
+		}
+	}
+	fmt.Println(recipe.Message)
+	output := recipe.Output
+	if p.AvailableWeight < output.Weight {
+		p.CurrentRoom.Items[output.Name] = output
+		fmt.Printf("You can't carry %s, so you put it down.\n", output.Name)
+		return true
+	}
+	p.Inventory[output.Name] = output
+	p.ChangeCarriedWeight(output, "increase")
+	fmt.Printf("%s has been added to your inventory.\n", output.Name)
+	return true
+}
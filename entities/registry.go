@@ -0,0 +1,77 @@
+package entities
+
+type interactionKey struct {
+	verb   string
+	item   string
+	entity string
+}
+
+type Registry struct {
+	interactions []*Interaction
+	byKey        map[interactionKey]*Interaction
+	verbs        map[string]bool
+	events       map[string]*Event
+}
+
+var Index = NewRegistry()
+
+func NewRegistry(interactions ...*Interaction) *Registry {
+	r := &Registry{
+		byKey:  make(map[interactionKey]*Interaction),
+		verbs:  make(map[string]bool),
+		events: make(map[string]*Event),
+	}
+	r.Add(interactions...)
+	return r
+}
+
+func keyFor(interaction *Interaction) interactionKey {
+	if interaction.Verb != "" {
+		return interactionKey{verb: interaction.Verb, entity: interaction.EntityName}
+	}
+	return interactionKey{item: interaction.ItemName, entity: interaction.EntityName}
+}
+
+func (r *Registry) Add(interactions ...*Interaction) {
+	for _, interaction := range interactions {
+		r.interactions = append(r.interactions, interaction)
+		r.byKey[keyFor(interaction)] = interaction
+		if interaction.Verb != "" {
+			r.verbs[interaction.Verb] = true
+		}
+		r.RegisterEvent(interaction.Event)
+	}
+}
+
+func (r *Registry) RegisterEvent(events ...*Event) {
+	for _, event := range events {
+		r.events[event.ID] = event
+	}
+}
+
+func (r *Registry) FindUse(itemName string, entityName string) (*Interaction, bool) {
+	interaction, ok := r.byKey[interactionKey{item: itemName, entity: entityName}]
+	return interaction, ok
+}
+
+func (r *Registry) FindVerb(verb string, entityName string) (*Interaction, bool) {
+	interaction, ok := r.byKey[interactionKey{verb: verb, entity: entityName}]
+	return interaction, ok
+}
+
+func (r *Registry) HasVerb(verb string) bool {
+	return r.verbs[verb]
+}
+
+func (r *Registry) FindEvent(id string) *Event {
+	return r.events[id]
+}
+
+func (r *Registry) HasTriggered(id string) bool {
+	event, ok := r.events[id]
+	return ok && event.Triggered
+}
+
+func (r *Registry) Interactions() []*Interaction {
+	return r.interactions
+}
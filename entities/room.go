@@ -1,5 +1,9 @@
 package entities
 
+import "sort"
+
+var DirectionAliases = map[string]string{}
+
 type Room struct {
 	Name        string
 	Description string
@@ -13,5 +17,54 @@ func (r *Room) SetDescription(description string) {
 }
 
 func (r *Room) GetDescription() string {
-	return r.Description
+	return Render(r.Description)
+}
+
+func (r *Room) GetShortDescription() string {
+	return r.Name
+}
+
+func (r *Room) GetLongDescription() string {
+	return Render(r.Description)
+}
+
+func (r *Room) FindItem(name string) (*Item, bool) {
+	if item, ok := r.Items[name]; ok {
+		return item, true
+	}
+	for _, key := range sortedKeys(r.Items) {
+		if item := r.Items[key]; item.Matches(name) {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Room) FindEntity(name string) (*Entity, bool) {
+	if entity, ok := r.Entities[name]; ok {
+		return entity, true
+	}
+	for _, key := range sortedKeys(r.Entities) {
+		if entity := r.Entities[key]; entity.Matches(name) {
+			return entity, true
+		}
+	}
+	return nil, false
+}
+
+func sortedKeys[T any](objects map[string]T) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (r *Room) FindExit(direction string) (*Room, bool) {
+	if exit, ok := r.Exits[direction]; ok {
+		return exit, true
+	}
+	exit, ok := r.Exits[DirectionAliases[direction]]
+	return exit, ok
 }
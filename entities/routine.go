@@ -0,0 +1,71 @@
+package entities
+
+import "fmt"
+
+type Routine struct {
+	Entity    *Entity
+	Room      *Room
+	Route     []*Room
+	Every     int
+	Active    func() bool
+	Arrives   string
+	Leaves    string
+	position  int
+	turnsLeft int
+}
+
+func (r *Routine) Tick(p *Player) {
+	if len(r.Route) == 0 || (r.Active != nil && !r.Active()) {
+		return
+	}
+	if r.Room == nil {
+		r.position = 0
+		r.moveTo(r.Route[0], p)
+		return
+	}
+	if len(r.Route) < 2 {
+		return
+	}
+	if r.turnsLeft++; r.turnsLeft < r.Every {
+		return
+	}
+	r.turnsLeft = 0
+	r.position = (r.position + 1) % len(r.Route)
+	r.moveTo(r.Route[r.position], p)
+}
+
+func (r *Routine) moveTo(room *Room, p *Player) {
+	from := r.Room
+	if from == room {
+		return
+	}
+	if from != nil {
+		delete(from.Entities, r.Entity.Name)
+	}
+	room.Entities[r.Entity.Name] = r.Entity
+	r.Room = room
+
+	if from != nil && from == p.CurrentRoom {
+		if p.CurrentEntity == r.Entity {
+			p.CurrentEntity = nil
+		}
+		leaves := r.Leaves
+		if leaves == "" {
+			leaves = fmt.Sprintf("%s leaves for the %s.", r.Entity.Name, room.Name)
+		}
+		fmt.Println(leaves)
+	}
+	if room == p.CurrentRoom {
+		arrives := r.Arrives
+		if arrives == "" {
+			arrives = fmt.Sprintf("%s walks in.", r.Entity.Name)
+		}
+		fmt.Println(arrives)
+	}
+}
+
+func TickRoutines(routines []*Routine, p *Player) {
+	for _, routine := range routines {
+		routine.Tick(p)
+	}
+}
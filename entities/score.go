@@ -0,0 +1,77 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ScoreRule struct {
+	Label  string
+	Points int
+}
+
+type ScoreEntry struct {
+	Label  string
+	Points int
+	Count  int
+}
+
+type Scoring struct {
+	Milestones map[string]ScoreRule
+	Penalties  map[string]ScoreRule
+	Entries    []*ScoreEntry
+	awarded    map[string]bool
+}
+
+func NewScoring(milestones map[string]ScoreRule, penalties map[string]ScoreRule) *Scoring {
+	return &Scoring{Milestones: milestones, Penalties: penalties, awarded: make(map[string]bool)}
+}
+
+func (s *Scoring) Record(eventID string) {
+	rule, ok := s.Milestones[eventID]
+	if !ok || s.awarded[eventID] {
+		return
+	}
+	s.awarded[eventID] = true
+	s.add(rule)
+}
+
+func (s *Scoring) Penalise(name string) {
+	if rule, ok := s.Penalties[name]; ok {
+		s.add(rule)
+	}
+}
+
+func (s *Scoring) add(rule ScoreRule) {
+	for _, entry := range s.Entries {
+		if entry.Label == rule.Label {
+			entry.Points += rule.Points
+			entry.Count++
+			return
+		}
+	}
+	s.Entries = append(s.Entries, &ScoreEntry{Label: rule.Label, Points: rule.Points, Count: 1})
+}
+
+func (s *Scoring) Total() int {
+	total := 0
+	for _, entry := range s.Entries {
+		total += entry.Points
+	}
+	return total
+}
+
+func (s *Scoring) Breakdown() string {
+	if len(s.Entries) == 0 {
+		return "No points scored yet."
+	}
+	lines := []string{}
+	for _, entry := range s.Entries {
+		label := entry.Label
+		if entry.Count > 1 {
+			label = fmt.Sprintf("%s x%d", entry.Label, entry.Count)
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %+d", label, entry.Points))
+	}
+	return strings.Join(lines, "\n")
+}
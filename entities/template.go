@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"academy-adventure-game/globalGame"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var templateFuncs = template.FuncMap{
+	"triggered": func(id string) bool {
+		return Index.HasTriggered(id)
+	},
+	"var": func(name string) (any, error) {
+		value, ok := globalGame.Vars.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %q", name)
+		}
+		return value, nil
+	},
+}
+
+var templates = make(map[string]*template.Template)
+
+func parseTemplate(text string) (*template.Template, error) {
+	if tmpl, ok := templates[text]; ok {
+		return tmpl, nil
+	}
+	tmpl, err := template.New("description").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	templates[text] = tmpl
+	return tmpl, nil
+}
+
+func Render(text string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		reportTemplateError(err)
+		return text
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, nil); err != nil {
+		reportTemplateError(err)
+		return text
+	}
+	return out.String()
+}
+
+func reportTemplateError(err error) {
+	if globalGame.Debug {
+		fmt.Println("debug: template error:", err)
+	}
+}
@@ -0,0 +1,144 @@
+package entities
+
+import "fmt"
+
+func ValidateWorld(rooms []*Room, routines []*Routine) []error {
+	problems := []error{}
+	known := make(map[*Room]bool)
+	items := make(map[string]bool)
+	entities := make(map[string]bool)
+	for _, room := range rooms {
+		known[room] = true
+	}
+	for _, room := range rooms {
+		for direction, exit := range room.Exits {
+			if !known[exit] {
+				problems = append(problems, fmt.Errorf("%s: exit %s leads to a room that is not in the world", room.Name, direction))
+			}
+		}
+		problems = append(problems, templateProblems(room.Name, room.Description)...)
+		for name, item := range room.Items {
+			items[name] = true
+			problems = append(problems, itemTemplateProblems(item)...)
+		}
+		for name, entity := range room.Entities {
+			entities[name] = true
+			problems = append(problems, entityTemplateProblems(entity)...)
+			for itemName, item := range entity.Inventory {
+				items[itemName] = true
+				problems = append(problems, itemTemplateProblems(item)...)
+			}
+		}
+	}
+	for _, routine := range routines {
+		if !entities[routine.Entity.Name] {
+			entities[routine.Entity.Name] = true
+			problems = append(problems, entityTemplateProblems(routine.Entity)...)
+		}
+		for itemName := range routine.Entity.Inventory {
+			items[itemName] = true
+		}
+	}
+	for _, recipe := range ValidRecipes {
+		if recipe.Output != nil {
+			items[recipe.Output.Name] = true
+		}
+	}
+
+	for _, interaction := range Index.Interactions() {
+		if interaction.ItemName != "" && !items[interaction.ItemName] {
+			problems = append(problems, fmt.Errorf("interaction %s: unknown item %s", interaction.Event.ID, interaction.ItemName))
+		}
+		if !entities[interaction.EntityName] {
+			problems = append(problems, fmt.Errorf("interaction %s: unknown entity %s", interaction.Event.ID, interaction.EntityName))
+		}
+		if interaction.Verb == "" && interaction.ItemName == "" {
+			problems = append(problems, fmt.Errorf("interaction %s: needs a verb or an item", interaction.Event.ID))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, recipe := range ValidRecipes {
+		switch {
+		case recipe.Output == nil:
+			problems = append(problems, fmt.Errorf("recipe has no output"))
+			continue
+		case len(recipe.Inputs) != 2:
+			problems = append(problems, fmt.Errorf("recipe for %s: needs exactly two inputs, has %d", recipe.Output.Name, len(recipe.Inputs)))
+			continue
+		}
+		for _, ingredient := range recipe.Inputs {
+			if !items[ingredient.Name] {
+				problems = append(problems, fmt.Errorf("recipe for %s: unknown input %s", recipe.Output.Name, ingredient.Name))
+			}
+			if ingredient.Name == recipe.Output.Name {
+				problems = append(problems, fmt.Errorf("recipe for %s: output cannot also be an input", recipe.Output.Name))
+			}
+		}
+		if recipe.Inputs[0].Name == recipe.Inputs[1].Name {
+			problems = append(problems, fmt.Errorf("recipe for %s: inputs must be two different items", recipe.Output.Name))
+		}
+		pair := recipe.Inputs[0].Name + "+" + recipe.Inputs[1].Name
+		if recipe.Inputs[1].Name < recipe.Inputs[0].Name {
+			pair = recipe.Inputs[1].Name + "+" + recipe.Inputs[0].Name
+		}
+		if seen[pair] {
+			problems = append(problems, fmt.Errorf("recipe for %s: %s are already combined by another recipe", recipe.Output.Name, pair))
+		}
+		seen[pair] = true
+	}
+	return problems
+}
+
+func itemTemplateProblems(item *Item) []error {
+	texts := []string{item.Description, item.ShortDescription, item.LongDescription}
+	for _, text := range item.StateDescriptions {
+		texts = append(texts, text)
+	}
+	return templateProblems(item.Name, texts...)
+}
+
+func machineProblems(entity *Entity) []error {
+	problems := []error{}
+	if entity.Machine == nil {
+		return problems
+	}
+	if _, ok := entity.Machine.States[entity.State]; !ok {
+		problems = append(problems, fmt.Errorf("%s: starts in unknown state %q", entity.Name, entity.State))
+	}
+	for from, targets := range entity.Machine.Transitions {
+		if _, ok := entity.Machine.States[from]; !ok {
+			problems = append(problems, fmt.Errorf("%s: transition from unknown state %q", entity.Name, from))
+		}
+		for _, to := range targets {
+			if _, ok := entity.Machine.States[to]; !ok {
+				problems = append(problems, fmt.Errorf("%s: transition to unknown state %q", entity.Name, to))
+			}
+		}
+	}
+	for name, state := range entity.Machine.States {
+		problems = append(problems, templateProblems(entity.Name+" ("+name+")", state.Description, state.LongDescription)...)
+	}
+	return problems
+}
+
+func entityTemplateProblems(entity *Entity) []error {
+	texts := []string{entity.Description, entity.ShortDescription, entity.LongDescription}
+	for _, text := range entity.StateDescriptions {
+		texts = append(texts, text)
+	}
+	for _, text := range entity.MoodDescriptions {
+		texts = append(texts, text)
+	}
+	return append(templateProblems(entity.Name, texts...), machineProblems(entity)...)
+}
+
+func templateProblems(owner string, texts ...string) []error {
+	problems := []error{}
+	for _, text := range texts {
+		if _, err := parseTemplate(text); err != nil {
+			problems = append(problems, fmt.Errorf("%s: bad description template: %v", owner, err))
+		}
+	}
+	return problems
+}
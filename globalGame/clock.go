@@ -0,0 +1,79 @@
+package globalGame
+
+import "fmt"
+
+var StartTime = 13 * 60
+var Deadline = 16 * 60
+var CurrentTime = StartTime
+var Turn = 0
+
+var DefaultVerbCost = 1
+var VerbCosts = map[string]int{
+	"exit":         0,
+	"commands":     0,
+	"alias":        0,
+	"unalias":      0,
+	"aliases":      0,
+	"inventory":    0,
+	"map":          0,
+	"score":        0,
+	"hint":         0,
+	"achievements": 0,
+	"debug":        0,
+	"look":         1,
+	"examine":      1,
+	"leave":        1,
+	"drop":         1,
+	"read":         1,
+	"take":         2,
+	"push":         2,
+	"wake":         2,
+	"pet":          1,
+	"switch":       1,
+	"switch-on":    1,
+	"drink":        2,
+	"approach":     2,
+	"ask":          2,
+	"use":          3,
+	"give":         3,
+	"insert":       3,
+	"combine":      3,
+	"talk":         3,
+	"wait":         3,
+	"move":         5,
+}
+
+func VerbCost(verb string) int {
+	if cost, ok := VerbCosts[verb]; ok {
+		return cost
+	}
+	return DefaultVerbCost
+}
+
+func AdvanceClock(verb string) int {
+	return Spend(VerbCost(verb))
+}
+
+func Spend(cost int) int {
+	if cost > 0 {
+		CurrentTime += cost
+		Turn++
+	}
+	return cost
+}
+
+func FormatTime(minutes int) string {
+	hour := (minutes / 60) % 24
+	suffix := "am"
+	if hour >= 12 {
+		suffix = "pm"
+	}
+	if hour%12 == 0 {
+		return fmt.Sprintf("12:%02d%s", minutes%60, suffix)
+	}
+	return fmt.Sprintf("%d:%02d%s", hour%12, minutes%60, suffix)
+}
+
+func StatusLine() string {
+	return fmt.Sprintf("Time: %s | Turn: %d | Deadline: %s", FormatTime(CurrentTime), Turn, FormatTime(Deadline))
+}
@@ -0,0 +1,3 @@
+package globalGame
+
+var Debug = false
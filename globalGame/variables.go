@@ -0,0 +1,109 @@
+package globalGame
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Variables struct {
+	bools   map[string]bool
+	ints    map[string]int
+	strings map[string]string
+}
+
+var Vars = NewVariables()
+
+func NewVariables() *Variables {
+	return &Variables{
+		bools:   make(map[string]bool),
+		ints:    make(map[string]int),
+		strings: make(map[string]string),
+	}
+}
+
+func (v *Variables) SetBool(name string, value bool) {
+	v.bools[name] = value
+}
+
+func (v *Variables) Bool(name string) bool {
+	return v.bools[name]
+}
+
+func (v *Variables) SetInt(name string, value int) {
+	v.ints[name] = value
+}
+
+func (v *Variables) Int(name string) int {
+	return v.ints[name]
+}
+
+func (v *Variables) AddInt(name string, delta int) int {
+	v.ints[name] += delta
+	return v.ints[name]
+}
+
+func (v *Variables) SetString(name string, value string) {
+	v.strings[name] = value
+}
+
+func (v *Variables) String(name string) string {
+	return v.strings[name]
+}
+
+func (v *Variables) Lookup(name string) (any, bool) {
+	if value, ok := v.bools[name]; ok {
+		return value, true
+	}
+	if value, ok := v.ints[name]; ok {
+		return value, true
+	}
+	if value, ok := v.strings[name]; ok {
+		return value, true
+	}
+	return nil, false
+}
+
+type variablesJSON struct {
+	Bools   map[string]bool   `json:"bools"`
+	Ints    map[string]int    `json:"ints"`
+	Strings map[string]string `json:"strings"`
+}
+
+func (v *Variables) MarshalJSON() ([]byte, error) {
+	return json.Marshal(variablesJSON{Bools: v.bools, Ints: v.ints, Strings: v.strings})
+}
+
+func (v *Variables) UnmarshalJSON(data []byte) error {
+	var decoded variablesJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*v = *NewVariables()
+	for name, value := range decoded.Bools {
+		v.bools[name] = value
+	}
+	for name, value := range decoded.Ints {
+		v.ints[name] = value
+	}
+	for name, value := range decoded.Strings {
+		v.strings[name] = value
+	}
+	return nil
+}
+
+func (v *Variables) Dump() string {
+	lines := []string{}
+	for name, value := range v.bools {
+		lines = append(lines, fmt.Sprintf("%s = %t", name, value))
+	}
+	for name, value := range v.ints {
+		lines = append(lines, fmt.Sprintf("%s = %d", name, value))
+	}
+	for name, value := range v.strings {
+		lines = append(lines, fmt.Sprintf("%s = %q", name, value))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"academy-adventure-game/commands"
 	"academy-adventure-game/entities"
 	"academy-adventure-game/globalGame"
-	"bufio"
+	"academy-adventure-game/profile"
+	"academy-adventure-game/prompt"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -23,25 +28,179 @@ func clearScreen() {
 }
 
 func showCommands() {
-	fmt.Println("-exit -> quits the game\n\n-commands -> shows the commands\n\n-look -> shows the content of the room.\n\n-approach <entity> -> to approach an entity\n\n-leave -> to leave an entity\n\n-inventory -> shows items in the inventory\n\n-take <item> -> to take an item into your inventory\n\n-drop <item> -> to drop an item from your inventory and move it to the current room\n\n-use <item> -> to make use of a certain item when you approach an entity\n\n-move <direction> -> to move to a different room\n\n-map -> shows the directions you can take")
+	fmt.Println("-exit -> quits the game\n\n-commands -> shows the commands\n\n-look -> shows the content of the room.\n\n-approach <entity> -> to approach an entity\n\n-talk to <entity> -> starts a conversation with someone\n\n-ask <entity> about <topic> -> asks someone about a topic\n\n-give <item> to <entity> -> hands an item to someone\n\n-leave -> to leave an entity\n\n-examine <thing> -> takes a closer look at an item or entity\n\n-inventory -> shows items in the inventory\n\n-take <item> -> to take an item into your inventory\n\n-drop <item> -> to drop an item from your inventory and move it to the current room\n\n-use <item> -> to make use of a certain item when you approach an entity\n\n-drink <item> -> drinks from an item in your inventory\n\n-read <thing> -> reads what is written on an item or entity\n\n-push <entity> -> gives something a shove\n\n-wake <entity> -> wakes someone up\n\n-pet <entity> -> strokes an animal\n\n-switch on <entity> -> turns on a machine\n\n-insert <item> -> puts an item into the entity you have approached\n\n-combine <item> with <item> -> makes something new out of two items\n\n-wait -> lets a little time pass\n\n-move <direction> -> to move to a different room\n\n-map -> shows the directions you can take\n\n-score -> shows your score so far\n\n-hint -> gives you a nudge in the right direction\n\n-achievements -> shows the achievements you have unlocked\n\n-alias <name> = <commands> -> defines a shortcut for one or more commands\n\n-unalias <name> -> removes a shortcut\n\n-aliases -> shows your shortcuts")
+}
+
+func showEpilogue(endings *entities.Endings, playerProfile *profile.Profile, scoring *entities.Scoring, commandsEntered int) {
+	fmt.Println()
+	if ending := endings.Reached; ending != nil {
+		result := "Defeat"
+		if ending.Win {
+			result = "Victory"
+		}
+		fmt.Printf("Ending: %s (%s)\n%s\n\n", ending.Title, result, ending.Summary)
+	} else {
+		fmt.Print("Ending: none. You left before the story was over.\n\n")
+	}
+	fmt.Printf("Turns taken: %d\nCommands entered: %d\nTime: %s\n\n", globalGame.Turn, commandsEntered, globalGame.FormatTime(globalGame.CurrentTime))
+	fmt.Printf("Final score: %d\n%s\n\n", scoring.Total(), scoring.Breakdown())
+	seen := 0
+	for _, ending := range endings.All() {
+		if playerProfile.HasSeenEnding(ending.ID) {
+			seen++
+		}
+	}
+	fmt.Printf("Endings discovered: %d of %d\n", seen, len(endings.All()))
+	for _, ending := range endings.All() {
+		if playerProfile.HasSeenEnding(ending.ID) {
+			fmt.Printf("- %s\n", ending.Title)
+		} else {
+			fmt.Println("- ???")
+		}
+	}
+	fmt.Println()
+}
+
+func showAliases(aliases map[string]string) {
+	if len(aliases) == 0 {
+		fmt.Println("You have not defined any aliases.")
+		return
+	}
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s = %s\n", name, aliases[name])
+	}
+}
+
+func disambiguationPrompt(candidates []string) string {
+	if len(candidates) == 1 {
+		return fmt.Sprintf("Did you mean %s?", candidates[0])
+	}
+	return fmt.Sprintf("Which do you mean: %s or %s?", strings.Join(candidates[:len(candidates)-1], ", "), candidates[len(candidates)-1])
+}
+
+func completionCandidates(player *entities.Player, aliases map[string]string, line string) []string {
+	if i := strings.LastIndexAny(line, ",;"); i >= 0 {
+		line = line[i+1:]
+	}
+	words := strings.Fields(line)
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	for i := len(words) - 2; i >= 0; i-- {
+		if words[i] == "then" || words[i] == "and" {
+			words = words[i+1:]
+			break
+		}
+	}
+	if len(words) == 1 {
+		candidates := append([]string{}, commands.Names...)
+		for name := range aliases {
+			candidates = append(candidates, name)
+		}
+		sort.Strings(candidates)
+		return candidates
+	}
+	switch words[0] {
+	case "move":
+		directions := []string{}
+		for direction := range player.CurrentRoom.Exits {
+			directions = append(directions, direction)
+		}
+		sort.Strings(directions)
+		return directions
+	case "take":
+		return player.VisibleNames(entities.RoomItems)
+	case "drop", "use", "drink", "insert", "combine":
+		return player.VisibleNames(entities.InventoryItems)
+	case "approach", "talk", "ask", "push", "wake", "pet", "switch-on":
+		return player.VisibleNames(entities.RoomEntities)
+	case "give":
+		if len(words) == 2 {
+			return player.VisibleNames(entities.InventoryItems)
+		}
+		return player.VisibleNames(entities.RoomEntities)
+	case "examine":
+		return player.VisibleNames(entities.RoomItems | entities.RoomEntities | entities.InventoryItems)
+	case "read":
+		return player.VisibleNames(entities.RoomEntities | entities.InventoryItems)
+	}
+	return nil
+}
+
+type pendingChoice struct {
+	command    string
+	rest       string
+	candidates []string
 }
 
 func main() {
+	profileName := flag.String("profile", "default", "name of the player profile to use")
+	debug := flag.Bool("debug", false, "enable debug commands")
+	flag.Parse()
+	globalGame.Debug = *debug
+
+	playerProfile, err := profile.Load(*profileName)
+	profileLoaded := err == nil
+	if !profileLoaded {
+		fmt.Println("Could not load your profile:", err)
+		fmt.Println("Your progress will not be saved this game.")
+	}
+	for _, name := range commands.PruneAliases(playerProfile.Aliases) {
+		fmt.Printf("Your alias %s is now a command, so it has been removed.\n", name)
+	}
+
 	introduction := "It's the last day at the Academy, and you and your fellow graduates are ready to take on the final hack-day challenge.\nHowever, this time, it's different. Alan and Dan, your instructors, have prepared something more intense than ever before — a true test of your problem-solving and coding skills.\nThe doors to the academy are locked, the windows sealed. The only way out is to find and solve a series of riddles that lead to the terminal in a hidden room.\nThe challenge? Crack the code on the terminal to unlock the doors. But it's not that simple.\nYou'll need to gather items, approach Alan and Dan for cryptic tips, and outsmart the obstacles they've laid out for you.\nAs the tension rises, only your wits, teamwork, and knowledge can guide you to freedom.\nAre you ready to escape?\nOh and remember... You don't want to make Rosie grumpy! So don't do anything crazy.\n\nif at any point you feel lost, type 'commands' to display the list of all commands.\nThe command 'look' is always useful to get your bearings and see the options available to you.\nThe command 'exit' will make you quit the game at any time. Make sure you do mean to use it, or you will inadvertently lose all of your progress!"
 
 	introductionShown := false
 
 	entities.ValidInteraction()
+	entities.ValidRecipe()
 
-	dishwasherChallengeWon := &entities.Event{Description: "dishwasher-loaded", Outcome: "You load the dirty plates into the dishwasher and switch it on, a feeling of being used washing over you.\nThis challenge felt less like teamwork and more like being roped into someone else's mess.\nWith a sigh, you decide to head back to Alan to see if this effort has truly led you to victory...\n", Triggered: false}
+	dishwasherChallengeWon := &entities.Event{ID: "dishwasher-loaded", Outcome: "The dishwasher beeps three times and falls silent: the cycle is complete.\nThis challenge felt less like teamwork and more like being roped into someone else's mess.\nWith a sigh, you decide to head back to Alan to see if this effort has truly led you to victory...\n", Triggered: false}
 
-	grumpyRosie := &entities.Event{Description: "rosie-is-grumpy", Outcome: "Rosie caught you in the act of swiping a lanyard from a fellow student.\nYou have made Rosie grumpy and you've lost the game.\n", Triggered: false}
+	kettleBoiled := &entities.Event{ID: "kettle-boiled", Outcome: "The kettle clicks off. You brew the strongest cup of tea you've ever made, and a comforting aroma fills the room.\n", Triggered: false}
 
-	unlockComputer := &entities.Event{Description: "computer-is-unlocked", Outcome: "You enter the password, holding your breath. Yes! The screen flickers to life.\nyou've unlocked the computer and now have full access.\n\nYou should approach Alan to find out what's next...\n", Triggered: false}
+	caffeinated := &entities.Event{ID: "tea-drunk", Outcome: "The caffeine kicks in. You feel quicker on your feet already.\n", Triggered: false}
+
+	grumpyRosie := &entities.Event{ID: "rosie-is-grumpy", Outcome: "Rosie has had enough of your antics.\nYou have made Rosie grumpy and you've lost the game.\n", Triggered: false}
+
+	lanyardTheftWitnessed := &entities.Event{ID: "lanyard-theft-witnessed", Outcome: "You've been caught in the act of swiping a lanyard from a fellow student.\n", Triggered: false}
+
+	unlockComputer := &entities.Event{ID: "computer-is-unlocked", Outcome: "You enter the password, holding your breath. Yes! The screen flickers to life.\nyou've unlocked the computer and now have full access.\n\nYou should approach Alan to find out what's next...\n", Triggered: false}
+
+	exitsUnlocked := &entities.Event{ID: "exits-unlocked", Outcome: "As you execute the final command, the terminal whirs to life, and the screen fills with a flurry of colorful text.\nThe words 'Victory Achieved!' flash across the display, illuminating your face with a soft glow.\nYou feel a rush of adrenaline as the file containing the instructions to unlock the exits appears before you.\nFollowing the instructions carefully, you swiftly input the necessary commands, and with a satisfying beep, the locks on the exits click open.\nThe room is filled with the sound of machinery grinding to a halt as the doors swing wide.", Triggered: false}
+
+	computerLocked := &entities.Event{ID: "computer-locked", Outcome: "Alan's computer is locked, halting your progress in the challenge. To top it off, you've made Rosie grumpy, as she'll now have to take the computer to IT.\n", Triggered: false}
+
+	deadlineMissed := &entities.Event{ID: "deadline-missed", Outcome: "The clock strikes four. Alan and Dan grab their coats and switch off the lights, leaving you locked inside.\nYou ran out of time and you've lost the game.\n", Triggered: false}
+
+	timedEvents := []*entities.TimedEvent{
+		{At: 15 * 60, Event: &entities.Event{ID: "one-hour-left", Outcome: "Alan's voice echoes down the corridor: \"One hour to go, everyone!\"\n"}},
+		{At: 15*60 + 45, Event: &entities.Event{ID: "fifteen-minutes-left", Outcome: "Dan shouts from somewhere nearby: \"Fifteen minutes left! My train won't wait!\"\n"}},
+		{At: globalGame.Deadline, Event: deadlineMissed},
+	}
+
+	entities.Index.RegisterEvent(dishwasherChallengeWon, kettleBoiled, caffeinated, grumpyRosie, lanyardTheftWitnessed, unlockComputer, computerLocked, exitsUnlocked, deadlineMissed, entities.PlatesSmashed)
+	for _, timedEvent := range timedEvents {
+		entities.Index.RegisterEvent(timedEvent.Event)
+	}
 
 	computerPassword := "iiwsccrtc"
 
-	remainingPasswordAttempts := 10
+	vars := globalGame.Vars
+	vars.SetInt("password-attempts-left", 10)
+	vars.SetInt("wrong-passwords", 0)
+	vars.SetBool("in-secret-files", false)
+	vars.SetInt("tea-brews", 0)
+	vars.SetInt("tea-brewed-at", 0)
+	vars.SetInt("tea-cools-after", 12)
+	vars.SetBool("caffeinated", false)
+	vars.SetInt("caffeinated-move-cost", 3)
 
 	staffRoom := entities.Room{
 		Name:        "break-room",
@@ -72,30 +231,167 @@ func main() {
 	codingLab.Exits["east"] = &terminalRoom
 	terminalRoom.Exits["west"] = &codingLab
 
-	rosie := entities.Entity{Name: "rosie", Description: "Ugh, what? Sorry, I can't think straight without a brew. Get me some tea, and then we'll talk...", Hidden: false}
-	kettle := entities.Entity{Name: "kettle", Description: "You set the kettle to boil, brewing the strongest cup of tea you've ever made. A comforting aroma fills the room as the tea is now ready.\n\n(tea can now be found in the room)\n", Hidden: false}
-	sofa := entities.Entity{Name: "sofa", Description: "You come across one of your fellow academy students fast asleep on the sofa. Next to them, their lanyard lies carelessly within reach.\nYou know you shouldn't take it, but the temptation lingers...\n\n(abandoned-lanyard can now be found in the room)\n", Hidden: false}
-	tea := entities.Item{Name: "tea", Description: "A steaming cup of Yorkshire tea, rich and comforting.", Weight: 2, Hidden: true}
-	lanyard := entities.Item{Name: "lanyard", Description: "Your lanyard, a key to unlocking any door within the building.", Weight: 1, Hidden: true}
-	abandonedLanyard := entities.Item{Name: "abandoned-lanyard", Description: "An abandoned lanyard, a key to unlocking any door within the building.", Weight: 1, Hidden: true}
-	computer := entities.Entity{Name: "computer", Description: "Alan's computer. You need the password to get in.\n\nRemaining attempts: 10.\n\nType 'leave' to stop entering the password.\n\nEnter the password:\n", Hidden: false}
-	alan := entities.Entity{Name: "alan", Description: "Oh, you've finally made it... What are you waiting for, crack on with the code. The computer is right there...\nWhat's that? You don't know the password? Hmm... I seem to have forgotten it myself, but I do recall it's nine letters long.\nAnd for the love of all that's good, it's definitely not 'waterfall'!", Hidden: false}
-	agileManifesto := entities.Entity{Name: "agile-manifesto", Description: "A large, framed document hangs prominently on the wall, its edges slightly frayed\nYou can almost feel the energy of past brainstorming sessions in the air as you read the four key values:\n\nIndividuals and Interactions over processes and tools.\n\nWorking Software over comprehensive documentation.\n\nCustomer Collaboration over contract negotiation.\n\nResponding To Change over following a plan.\n", Hidden: false}
-	desk := entities.Entity{Name: "desk", Description: "You approach the desk and spot a messy pile of dirty plates, stacked haphazardly. You think to yourself that somebody was too lazy to load the dishwasher.\nThe stack is too heavy to carry all the plates at once, and taking plates from the centre or bottom of the stack could pose a risk...\n\n(stack of plates can now be found in the room)\n\n", Hidden: true}
-	dishwasher := entities.Entity{Name: "dishwasher", Description: "A stainless steel dishwasher sits quietly in the corner, its door slightly ajar.\nThe faint scent of soap lingers, and the racks inside are half-empty, waiting for the next load of dirty dishes to be placed inside.\nIt hums faintly, as if anticipating the task it was built for.", Hidden: true}
-	firstPlate := entities.Item{Name: "first-plate", Description: "The plate on top of the stack.", Weight: 6, Hidden: true}
-	secondPlate := entities.Item{Name: "second-plate", Description: "The second plate of the stack.", Weight: 6, Hidden: true}
-	thirdPlate := entities.Item{Name: "third-plate", Description: "The third plate of the stack.", Weight: 6, Hidden: true}
-	fourthPlate := entities.Item{Name: "fourth-plate", Description: "The fourth plate of the stack.", Weight: 6, Hidden: true}
-	fifthPlate := entities.Item{Name: "fifth-plate", Description: "The fifth plate of the stack.", Weight: 6, Hidden: true}
-	sixthPlate := entities.Item{Name: "sixth-plate", Description: "The plate at the bottom of the stack.", Weight: 6, Hidden: true}
-	terminal := entities.Entity{Name: "terminal", Description: "A sleek terminal sits on the desk, its screen displaying lines of code and system commands.\nThe keyboard, slightly worn, hints at frequent use.\nThis device is essential for executing tasks and accessing the building's network.\n\nEnter your commands below or type 'leave' to exit the terminal.\n\n", Hidden: true}
-	dan := entities.Entity{Name: "dan", Description: "Congratulations on making it this far! I must say, I'm genuinely impressed. It appears I'm your final boss — muahahaha!\n...Oh, pardon my theatrics. Now, listen closely: the terminal holds the secret instructions to escape the building.\nYou only need two commands to access them.\nLook around the building to find some clues...\nYes, I know, this actually the easiest task so far. If I am being totally honest, we just want to be done by 4pm...\nWhat are you standing there for? Get to it!\n", Hidden: true}
-	cd := entities.Item{Name: "cd", Description: "A compact disc with '\\secret-files' written on it in bold letters.\nIt almost seems to call out to you, hinting at hidden knowledge.", Weight: 1, Hidden: false}
-	cat := entities.Entity{Name: "cat", Description: "On one of the chairs, a fluffy cat lounges lazily, wearing a collar with a name tag that reads 'unlock-exits-instructions.txt'\n\nAn odd name for a cat. You get the feeling that this feline is more than it seems, possibly guarding crucial information", Hidden: false}
+	entities.DirectionAliases = map[string]string{"n": "north", "s": "south", "e": "east", "w": "west"}
+
+	rosie := entities.Entity{Name: "rosie", Description: "{{if triggered \"get-your-lanyard\"}}Can I help with anything else?{{else}}Ugh, what? Sorry, I can't think straight without a brew. Get me some tea, and then we'll talk...{{end}}", LongDescription: "Rosie keeps the academy running, from the break-room rota to every student's lanyard. She is rarely seen without a mug in hand — except, it seems, today.", StateDescriptions: map[string]string{"refreshed": "Rosie sips her tea contentedly, lost in thought and finally ready to face the day."}, Hidden: false, Watchful: true}
+	kettle := entities.Entity{Name: "kettle", LongDescription: "A well-used kettle with a limescale-streaked window. A note stuck to it reads: 'Rosie's — do not let it run dry'.", State: "idle", Hidden: false}
+	sofa := entities.Entity{Name: "sofa", LongDescription: "A battered sofa with one of your fellow academy students curled up on it, snoring softly.", State: "asleep", Hidden: false, Aliases: []string{"student"}, Watchful: true, Asleep: true}
+	tea := entities.Item{Name: "tea", Description: "A steaming cup of Yorkshire tea, rich and comforting.", Weight: 2, LongDescription: "A mug of Yorkshire tea, brewed strong enough to stand a spoon in. Steam still rises from it.", State: "hot", StateDescriptions: map[string]string{"cold": "A mug of Yorkshire tea that has gone stone cold. Rosie would never drink it like this.", "empty": "An empty mug with a ring of tea stain at the bottom."}, Hidden: true, Aliases: []string{"mug", "cup", "brew"}, Charges: 3, SpentState: "empty"}
+	lanyard := entities.Item{Name: "lanyard", Description: "Your lanyard, a key to unlocking any door within the building.", Weight: 1, LongDescription: "A lanyard with your name and photo on it. The card swipes open every door in the building.", Hidden: true}
+	abandonedLanyard := entities.Item{Name: "abandoned-lanyard", Description: "An abandoned lanyard, a key to unlocking any door within the building.", Weight: 1, LongDescription: "A lanyard belonging to the student asleep on the sofa. Taking it would be stealing, and Rosie would not approve.", Hidden: true, Contraband: true, CaughtEvent: lanyardTheftWitnessed, CaughtPenalty: 40}
+	computer := entities.Entity{Name: "computer", LongDescription: "Alan's computer, its lock screen glowing with a password prompt.", State: "locked", Hidden: false, Aliases: []string{"pc", "laptop"}}
+	alan := entities.Entity{Name: "alan", Description: "{{if triggered \"dishwasher-loaded\"}}Ah, so you've managed to load the dishwasher! Splendid work — consider this challenge complete.\nI could have done it myself instead of writing that clever recursive function, but where's the fun in that?\nAfter all, they pay me for my intellect, not for doing the heavy lifting!\nBut I digress. You're free to proceed to the terminal room and speak with Dan for your final challenge.\nYou're doing an excellent job; keep it up!{{else if triggered \"computer-is-unlocked\"}}You've cracked the password! Impressive work... You should now see an open file containing a recursive function.\n\nFollow its instructions carefully, and you'll be one step closer to victory!\nBut, a word of caution: the task ahead is, well, a bit more hands-on than you might expect...{{else}}Oh, you've finally made it... What are you waiting for, crack on with the code. The computer is right there...\nWhat's that? You don't know the password? Hmm... I seem to have forgotten it myself, but I do recall it's nine letters long.\nAnd for the love of all that's good, it's definitely not 'waterfall'!{{end}}", LongDescription: "Alan, one of your instructors, leans back in his chair with the satisfied air of someone who has set a puzzle he knows you'll struggle with.", Hidden: false}
+	agileManifesto := entities.Entity{Name: "agile-manifesto", Description: "A large, framed document hangs prominently on the wall, its edges slightly frayed\nYou can almost feel the energy of past brainstorming sessions in the air as you read the four key values:\n\nIndividuals and Interactions over processes and tools.\n\nWorking Software over comprehensive documentation.\n\nCustomer Collaboration over contract negotiation.\n\nResponding To Change over following a plan.\n", LongDescription: "A framed copy of the agile manifesto. Some of its words are capitalised, as though they matter more than the rest.", Hidden: false}
+	desk := entities.Entity{Name: "desk", LongDescription: "A cluttered desk in the corner of the coding lab, buried under a stack of dirty plates.", State: "untouched", Hidden: true, Aliases: []string{"plates", "stack"}}
+	dishwasher := entities.Entity{Name: "dishwasher", Description: "A stainless steel dishwasher sits quietly in the corner, its door slightly ajar.\nThe faint scent of soap lingers, and the racks inside are half-empty, waiting for the next load of dirty dishes to be placed inside.\nIt hums faintly, as if anticipating the task it was built for.", LongDescription: "A stainless steel dishwasher with half-empty racks, waiting for a load of dirty dishes.", Hidden: true}
+	firstPlate := entities.Item{Name: "first-plate", Description: "The plate on top of the stack.", Weight: 6, State: "dirty", StateDescriptions: map[string]string{"clean": "A sparkling clean plate, fresh out of the dishwasher."}, Hidden: true}
+	secondPlate := entities.Item{Name: "second-plate", Description: "The second plate of the stack.", Weight: 6, State: "dirty", StateDescriptions: map[string]string{"clean": "A sparkling clean plate, fresh out of the dishwasher."}, Hidden: true}
+	thirdPlate := entities.Item{Name: "third-plate", Description: "The third plate of the stack.", Weight: 6, State: "dirty", StateDescriptions: map[string]string{"clean": "A sparkling clean plate, fresh out of the dishwasher."}, Hidden: true}
+	fourthPlate := entities.Item{Name: "fourth-plate", Description: "The fourth plate of the stack.", Weight: 6, State: "dirty", StateDescriptions: map[string]string{"clean": "A sparkling clean plate, fresh out of the dishwasher."}, Hidden: true}
+	fifthPlate := entities.Item{Name: "fifth-plate", Description: "The fifth plate of the stack.", Weight: 6, State: "dirty", StateDescriptions: map[string]string{"clean": "A sparkling clean plate, fresh out of the dishwasher."}, Hidden: true}
+	sixthPlate := entities.Item{Name: "sixth-plate", Description: "The plate at the bottom of the stack.", Weight: 6, State: "dirty", StateDescriptions: map[string]string{"clean": "A sparkling clean plate, fresh out of the dishwasher."}, Hidden: true}
+	terminal := entities.Entity{Name: "terminal", Description: "A sleek terminal sits on the desk, its screen displaying lines of code and system commands.\nThe keyboard, slightly worn, hints at frequent use.\nThis device is essential for executing tasks and accessing the building's network.\n\nEnter your commands below or type 'leave' to exit the terminal.\n\n{{if var \"in-secret-files\"}}The terminal displays:\n\n/secret-files/\n\nIt looks like you are on the right track.\nEnter the final command to win the game!\n\nType 'leave' to stop entering commands on the terminal.\n{{end}}", LongDescription: "A sleek terminal on a polished wooden desk, its cursor blinking patiently.", Hidden: true}
+	dan := entities.Entity{Name: "dan", Description: "Congratulations on making it this far! I must say, I'm genuinely impressed. It appears I'm your final boss — muahahaha!\n...Oh, pardon my theatrics. Now, listen closely: the terminal holds the secret instructions to escape the building.\nYou only need two commands to access them.\nLook around the building to find some clues...\nYes, I know, this actually the easiest task so far. If I am being totally honest, we just want to be done by 4pm...\nWhat are you standing there for? Get to it!\n", LongDescription: "Dan, your other instructor, is pacing near the terminal and glancing at the clock.", Hidden: false}
+	cd := entities.Item{Name: "cd", Description: "A compact disc with '\\secret-files' written on it in bold letters.\nIt almost seems to call out to you, hinting at hidden knowledge.", Weight: 1, ShortDescription: "A compact disc labelled '\\secret-files'.", Hidden: false, Aliases: []string{"disc"}}
+	tornNote := entities.Item{Name: "torn-note", Description: "The top half of a torn note, covered in hurried handwriting.", Weight: 1, LongDescription: "The top half of a note. You can make out 'Note to self: the password is...' before the tear.", Hidden: false, Aliases: []string{"note"}}
+	noteScrap := entities.Item{Name: "note-scrap", Description: "A scrap of paper with half a sentence on it.", Weight: 1, LongDescription: "The bottom half of a torn note. It doesn't make much sense on its own.", Hidden: false, Aliases: []string{"scrap"}}
+	plates := []*entities.Item{&firstPlate, &secondPlate, &thirdPlate, &fourthPlate, &fifthPlate, &sixthPlate}
+	cat := entities.Entity{Name: "cat", Description: "On one of the chairs, a fluffy cat lounges lazily, wearing a collar with a name tag that reads 'unlock-exits-instructions.txt'\n\nAn odd name for a cat. You get the feeling that this feline is more than it seems, possibly guarding crucial information", LongDescription: "A fluffy cat with a name tag reading 'unlock-exits-instructions.txt'. It watches you with knowing eyes.", Hidden: false, Aliases: []string{"feline"}}
+
+	rosie.Topics = []*entities.Topic{
+		{Keyword: "lanyard", Prompt: "Where is my lanyard?", Response: "Your lanyard? I've got it somewhere... I'll dig it out once I've had a cup of tea."},
+		{Keyword: "rules", Prompt: "Are there any rules I should know about?", Response: "Just the one: no taking what isn't yours. I'm looking at you, lanyard thieves."},
+		{Keyword: "tea", Prompt: "How was the tea?", Response: "That really hit the spot. Thank you, love.", Requires: entities.Index.FindEvent("get-your-lanyard")},
+		{Keyword: "sorry", Prompt: "Sorry about earlier...", Response: "Hmph. Don't let it happen again.", Mood: "annoyed"},
+	}
+	alan.Topics = []*entities.Topic{
+		{Keyword: "password", Prompt: "Can you give me a clue about the password?", Response: "I always say the agile manifesto holds all the answers. Especially the words that matter most."},
+		{Keyword: "function", Prompt: "What does the recursive function mean?", Response: "Read it carefully: deal with the top of the pile, then repeat until the pile is empty.\nSounds like a certain desk needs some attention...", Requires: unlockComputer},
+		{Keyword: "dishwasher", Prompt: "What should I do now?", Response: "Off to the terminal room with you. Dan is waiting.", Requires: dishwasherChallengeWon},
+	}
+	dan.Topics = []*entities.Topic{
+		{Keyword: "terminal", Prompt: "What should I do at the terminal?", Response: "Two commands. One to change into the right directory, one to read the right file.\nThe clues are around the building — have you looked closely at everything?"},
+		{Keyword: "deadline", Prompt: "Why do you want to be done by 4pm?", Response: "Some of us have a train to catch."},
+	}
+
+	kettle.Process = &entities.Process{
+		Entity:     &kettle,
+		Turns:      3,
+		Repeatable: true,
+		Starting:   "You switch the kettle on and it begins to rumble. The water will take a little while to boil.",
+		Progress:   "The kettle rumbles and hisses as the water heats up.",
+		Done:       kettleBoiled,
+	}
+	dishwasher.Process = &entities.Process{
+		Entity:   &dishwasher,
+		Turns:    4,
+		Starting: "The dishwasher whirs into life.",
+		Progress: "The dishwasher sloshes and hums.",
+		Done:     dishwasherChallengeWon,
+	}
+	processes := []*entities.Process{kettle.Process, dishwasher.Process}
+
+	kettle.Machine = &entities.StateMachine{
+		States: map[string]*entities.MachineState{
+			"idle":    {Description: "A kettle sits on the counter, filled and ready to go."},
+			"boiling": {Description: "The kettle is rumbling away. The water isn't ready yet."},
+			"boiled":  {Description: "A kettle — essential for survival, impossible to function without one nearby. Approach it again to put the kettle back on.", LongDescription: "The kettle is still warm from the last boil, steam curling from its spout."},
+		},
+		Transitions: map[string][]string{"idle": {"boiling"}, "boiling": {"boiled"}, "boiled": {"boiling"}},
+	}
+	sofa.Machine = &entities.StateMachine{
+		States: map[string]*entities.MachineState{
+			"asleep": {Description: "You come across one of your fellow academy students fast asleep on the sofa. Next to them, their lanyard lies carelessly within reach.\nYou know you shouldn't take it, but the temptation lingers...\n\n(abandoned-lanyard can now be found in the room)\n"},
+			"lanyard-spotted": {Description: "Your fellow academy student continues to sleep on the sofa. Something tells you it's down to you to get stuff done today...", OnEnter: func() {
+				abandonedLanyard.Hidden = false
+			}},
+			"awake": {Description: "Your fellow academy student is awake now, scrolling on their phone and keeping half an eye on their lanyard.", LongDescription: "A battered sofa with one of your fellow academy students slumped on it, very much awake.", OnEnter: func() {
+				sofa.Asleep = false
+				abandonedLanyard.Hidden = false
+			}},
+		},
+		Transitions: map[string][]string{"asleep": {"lanyard-spotted", "awake"}, "lanyard-spotted": {"awake"}},
+	}
+	computer.Machine = &entities.StateMachine{
+		States: map[string]*entities.MachineState{
+			"locked": {Description: "Alan's computer. You need the password to get in.\n\nRemaining attempts: {{var \"password-attempts-left\"}}.\n\nType 'leave' to stop entering the password.\n\nEnter the password:\n"},
+			"unlocked": {Description: "function completeTask(pile)\n   if pile == 0:\n      return 'Task Complete'\n   else:\n      completeTask(pile - 1)\n", LongDescription: "Alan's computer, unlocked. A file containing a recursive function is open on the screen.", OnEnter: func() {
+				desk.Hidden = false
+				dishwasher.Hidden = false
+			}},
+		},
+		Transitions: map[string][]string{"locked": {"unlocked"}},
+	}
+	desk.Machine = &entities.StateMachine{
+		States: map[string]*entities.MachineState{
+			"untouched": {Description: "You approach the desk and spot a messy pile of dirty plates, stacked haphazardly. You think to yourself that somebody was too lazy to load the dishwasher.\nThe stack is too heavy to carry all the plates at once, and taking plates from the centre or bottom of the stack could pose a risk...\n\n(stack of plates can now be found in the room)\n\n"},
+			"revealed": {Description: "Despite the disarray, it's clear this desk sees frequent use, with just enough space left to get work done.", OnEnter: func() {
+				for _, plate := range plates {
+					plate.Hidden = false
+				}
+			}},
+		},
+		Transitions: map[string][]string{"untouched": {"revealed"}},
+	}
+	stateful := []*entities.Entity{&computer, &desk, &kettle, &sofa}
+
+	scoring := entities.NewScoring(map[string]entities.ScoreRule{
+		"get-your-lanyard":        {Label: "Tea delivered to Rosie", Points: 10},
+		"computer-is-unlocked":    {Label: "Password cracked", Points: 20},
+		"first-plate-loaded":      {Label: "Plate loaded", Points: 5},
+		"second-plate-loaded":     {Label: "Plate loaded", Points: 5},
+		"third-plate-loaded":      {Label: "Plate loaded", Points: 5},
+		"fourth-plate-loaded":     {Label: "Plate loaded", Points: 5},
+		"fifth-plate-loaded":      {Label: "Plate loaded", Points: 5},
+		"sixth-plate-loaded":      {Label: "Plate loaded", Points: 5},
+		"dishwasher-loaded":       {Label: "Dishwasher cycle finished", Points: 10},
+		"exits-unlocked":          {Label: "Escaped the academy", Points: 50},
+		"lanyard-theft-witnessed": {Label: "Caught stealing a lanyard", Points: -15},
+	}, map[string]entities.ScoreRule{
+		"wrong-password": {Label: "Wrong password", Points: -2},
+		"hint":           {Label: "Hint taken", Points: -5},
+	})
+
+	vars.SetInt("commands-entered", 0)
+	vars.SetBool("touched-abandoned-lanyard", false)
+	endings := entities.NewEndings(
+		&entities.Ending{ID: "escaped", Title: "The Great Escape", Win: true, Event: exitsUnlocked.ID, Summary: "You cracked the terminal and walked out of the academy a graduate."},
+		&entities.Ending{ID: "smashed-plates", Title: "Butterfingers", Win: false, Event: entities.PlatesSmashed.ID, Summary: "The plates hit the floor, and so did your chances of escaping."},
+		&entities.Ending{ID: "grumpy-rosie", Title: "Rosie's Wrath", Win: false, Event: grumpyRosie.ID, Summary: "You pushed Rosie too far, and nobody gets past a grumpy Rosie."},
+		&entities.Ending{ID: "locked-computer", Title: "Locked Out", Win: false, Event: computerLocked.ID, Summary: "Alan's computer is off to IT, and your escape went with it."},
+		&entities.Ending{ID: "out-of-time", Title: "Lights Out", Win: false, Event: deadlineMissed.ID, Summary: "Four o'clock came and went, and you spent the night in the academy."},
+	)
+	endings.Track(entities.Happenings)
+
+	achievements := []*entities.Achievement{
+		{ID: "clean-hands", Title: "Clean Hands", Description: "Escape without a single wrong password.", Event: exitsUnlocked.ID, Condition: func() bool {
+			return vars.Int("wrong-passwords") == 0
+		}},
+		{ID: "speedrunner", Title: "Speedrunner", Description: "Escape in under 40 commands.", Event: exitsUnlocked.ID, Condition: func() bool {
+			return vars.Int("commands-entered") < 40
+		}},
+		{ID: "cat-person", Title: "Cat Person", Description: "Pet the cat.", Event: "cat-petted"},
+		{ID: "honest-graduate", Title: "Honest Graduate", Description: "Escape without ever touching the abandoned lanyard.", Event: exitsUnlocked.ID, Condition: func() bool {
+			return !vars.Bool("touched-abandoned-lanyard")
+		}},
+	}
+
+	tea.Actions = map[string]*entities.ItemAction{
+		"drink": {Message: "You take a long sip of the tea.", UsesCharge: true, Event: caffeinated},
+	}
+	cd.Actions = map[string]*entities.ItemAction{
+		"read": {Message: "Written on the label in bold letters: '\\secret-files'. Underneath, in smaller writing: 'cd here first'."},
+	}
+
+	rosie.Mood = &entities.Mood{Value: 50, GrumpyAt: 0, AnnoyedAt: 25, CheerfulAt: 70}
+	rosie.MoodDescriptions = map[string]string{"annoyed": "Rosie keeps a wary eye on you over the rim of her mug. You're on thin ice."}
+	rosie.Inventory = map[string]*entities.Item{lanyard.Name: &lanyard}
+	alan.Inventory = make(map[string]*entities.Item)
+	rosie.Reactions = map[string]string{abandonedLanyard.Name: "Rosie frowns. \"Where did you find this? I'll make sure it gets back to its owner.\""}
+	dan.Inventory = make(map[string]*entities.Item)
+	dan.Reactions = map[string]string{cd.Name: "Dan takes the disc and grins. \"Found my secret files, did you? Keep what's written on it in mind.\""}
 
 	staffRoom.Items[tea.Name] = &tea
-	staffRoom.Items[lanyard.Name] = &lanyard
 	staffRoom.Items[abandonedLanyard.Name] = &abandonedLanyard
 	staffRoom.Entities[rosie.Name] = &rosie
 	staffRoom.Entities[kettle.Name] = &kettle
@@ -103,6 +399,8 @@ func main() {
 	staffRoom.Entities[dishwasher.Name] = &dishwasher
 	staffRoom.Entities[cat.Name] = &cat
 	codingLab.Items[cd.Name] = &cd
+	codingLab.Items[tornNote.Name] = &tornNote
+	staffRoom.Items[noteScrap.Name] = &noteScrap
 	codingLab.Entities[computer.Name] = &computer
 	codingLab.Entities[alan.Name] = &alan
 	codingLab.Entities[agileManifesto.Name] = &agileManifesto
@@ -114,14 +412,48 @@ func main() {
 	codingLab.Items[fifthPlate.Name] = &fifthPlate
 	codingLab.Items[sixthPlate.Name] = &sixthPlate
 	terminalRoom.Entities[terminal.Name] = &terminal
-	terminalRoom.Entities[dan.Name] = &dan
+
+	routines := []*entities.Routine{
+		{
+			Entity: &rosie,
+			Room:   &staffRoom,
+			Route:  []*entities.Room{&staffRoom, &codingLab},
+			Every:  5,
+			Active: func() bool {
+				return entities.Index.HasTriggered("get-your-lanyard")
+			},
+			Arrives: "Rosie bustles in, mug in hand, checking that everyone is behaving.",
+			Leaves:  "Rosie drains her mug and heads off to check on the other room.",
+		},
+		{
+			Entity:  &cat,
+			Room:    &staffRoom,
+			Route:   []*entities.Room{&staffRoom, &codingLab, &terminalRoom, &codingLab},
+			Every:   4,
+			Arrives: "The cat pads in and settles on a nearby chair.",
+			Leaves:  "The cat stretches, yawns and wanders off.",
+		},
+		{
+			Entity: &dan,
+			Route:  []*entities.Room{&terminalRoom},
+			Active: func() bool {
+				return dishwasherChallengeWon.Triggered
+			},
+			Arrives: "Dan strides into the room, rubbing his hands together.",
+		},
+	}
+
+	if problems := entities.ValidateWorld([]*entities.Room{&staffRoom, &codingLab, &terminalRoom}, routines); len(problems) != 0 {
+		for _, problem := range problems {
+			fmt.Println("World data problem:", problem)
+		}
+		return
+	}
 
 	isAttemptingPassword := false
 
 	isAttemptingTerminal := false
 
-	IsFirstCommand := false
-
 	player := entities.Player{
 		CurrentRoom:     &staffRoom,
 		Inventory:       make(map[string]*entities.Item),
@@ -129,224 +461,669 @@ func main() {
 		CurrentEntity:   nil,
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
+	carryingPlate := func() bool {
+		for _, plate := range plates {
+			if _, carried := player.Inventory[plate.Name]; carried {
+				return true
+			}
+		}
+		return false
+	}
+
+	hints := entities.NewHints(5,
+		&entities.HintStage{ID: "deliver-tea", Applies: func() bool {
+			_, carried := player.Inventory[tea.Name]
+			return !entities.Index.HasTriggered("get-your-lanyard") && carried && tea.GetState() == "hot"
+		}, Tiers: []string{
+			"Somebody in the break room would love that tea.",
+			"Rosie can't think without a brew, and she only drinks it hot, so don't dawdle.",
+			"Go to the break room, approach rosie and use tea.",
+		}},
+		&entities.HintStage{ID: "reheat-tea", Applies: func() bool {
+			_, carried := player.Inventory[tea.Name]
+			return !entities.Index.HasTriggered("get-your-lanyard") && carried
+		}, Tiers: []string{
+			"That mug has seen better days.",
+			"Rosie won't drink cold tea, but the kettle can brew a fresh one.",
+			"Approach the kettle in the break room and wait for it to boil. Your mug is topped up with hot tea.",
+		}},
+		&entities.HintStage{ID: "brew-tea", Applies: func() bool { return !entities.Index.HasTriggered("get-your-lanyard") }, Tiers: []string{
+			"Rosie won't be much help until she's had something to drink.",
+			"Rosie can't think without a brew, and the kettle is in the break room.",
+			"Approach the kettle, wait for it to boil, then take the tea.",
+		}},
+		&entities.HintStage{ID: "find-computer", Applies: func() bool { return !unlockComputer.Triggered && player.CurrentRoom != &codingLab }, Tiers: []string{
+			"There's nothing more to do here for now.",
+			"Alan's computer is waiting for you in the coding lab.",
+			"Head to the coding lab: it is south of the break room and west of the terminal room.",
+		}},
+		&entities.HintStage{ID: "password", Applies: func() bool { return !unlockComputer.Triggered }, Tiers: []string{
+			"Alan says the agile manifesto holds all the answers.",
+			"Read the agile-manifesto here in the coding lab closely. Some of its words are capitalised.",
+			"Take the first letter of each capitalised word on the agile manifesto: the password is iiwsccrtc.",
+		}},
+		&entities.HintStage{ID: "carry-plate", Applies: carryingPlate, Tiers: []string{
+			"That plate won't clean itself.",
+			"Dirty plates belong in the dishwasher in the break room.",
+			"Move north to the break room, approach the dishwasher and use the plate you are carrying.",
+		}},
+		&entities.HintStage{ID: "plates", Applies: func() bool { return !entities.Index.HasTriggered("sixth-plate-loaded") }, Tiers: []string{
+			"Alan's recursive function is about dealing with the top of a pile, over and over.",
+			"The desk in the coding lab hides a stack of dirty plates. They need to go in the dishwasher, top plate first.",
+			"Go to the desk in the coding lab and take the top plate, starting with first-plate. Carry each one to the dishwasher before taking the next.",
+		}},
+		&entities.HintStage{ID: "dishwasher", Applies: func() bool { return !dishwasherChallengeWon.Triggered }, Tiers: []string{
+			"A full dishwasher doesn't run itself.",
+			"Switch on the dishwasher in the break room, then give it a few turns.",
+			"Type switch on dishwasher, then wait until the cycle finishes.",
+		}},
+		&entities.HintStage{ID: "find-terminal", Applies: func() bool { return !exitsUnlocked.Triggered && player.CurrentRoom != &terminalRoom }, Tiers: []string{
+			"The last puzzle isn't in this room.",
+			"A terminal has appeared in the terminal room, east of the coding lab.",
+			"Go to the terminal room and approach the terminal.",
+		}},
+		&entities.HintStage{ID: "directory", Applies: func() bool { return !vars.Bool("in-secret-files") }, Tiers: []string{
+			"Dan said you need two terminal commands. The first gets you into the right place.",
+			"The cd in the coding lab is labelled with the name of a folder.",
+			"Approach the terminal and type: cd /secret-files",
+		}},
+		&entities.HintStage{ID: "file", Applies: func() bool { return !exitsUnlocked.Triggered }, Tiers: []string{
+			"Somebody in the building is wearing a file name.",
+			"The cat's name tag reads unlock-exits-instructions.txt. Now you just need to print it.",
+			"At the terminal, type: cat unlock-exits-instructions.txt",
+		}},
+	)
 
-	for {
+	historyPath, err := profile.HistoryPath(*profileName)
+	if err != nil {
+		fmt.Println("Command history will not be saved:", err)
+	}
+
+	editor := prompt.New(historyPath, func(line string) []string {
+		return completionCandidates(&player, playerProfile.Aliases, line)
+	})
 
-		if player.CurrentEntity != nil && player.CurrentEntity.Name == "sofa" {
-			abandonedLanyard.Hidden = false
-			sofa.SetDescription("Your fellow academy student continues to sleep on the sofa. Something tells you it's down to you to get stuff done today...")
+	var pending *pendingChoice
+
+	isTalking := false
+
+	resolve := func(command string, term string, rest string, scope entities.Scope) (string, bool) {
+		name, candidates := player.Resolve(term, scope)
+		if candidates != nil {
+			pending = &pendingChoice{command: command, rest: rest, candidates: candidates}
+			fmt.Println(disambiguationPrompt(candidates))
+			return "", false
 		}
+		return name, true
+	}
 
-		if player.CurrentEntity != nil && player.CurrentEntity.Name == "kettle" {
-			tea.Hidden = false
-			kettle.SetDescription("A kettle — essential for survival, impossible to function without one nearby.")
+	transition := func(entity *entities.Entity, to string) bool {
+		if err := entity.Transition(to); err != nil {
+			if *debug {
+				fmt.Println("debug:", err)
+			}
+			return false
+		}
+		return true
+	}
+
+	entities.Happenings.Subscribe(entities.EntityApproached, func(happening entities.Happening) {
+		switch happening.Entity {
+		case &sofa:
+			if sofa.GetState() == "asleep" {
+				transition(&sofa, "lanyard-spotted")
+			}
+		case &desk:
+			if desk.GetState() == "untouched" {
+				transition(&desk, "revealed")
+			}
+		case &kettle:
+			if kettle.Process.Start() {
+				transition(&kettle, "boiling")
+			}
 		}
+	})
+	entities.Happenings.Subscribe(entities.ItemTaken, func(happening entities.Happening) {
+		if happening.Item == &abandonedLanyard {
+			vars.SetBool("touched-abandoned-lanyard", true)
+		}
+	})
+	entities.Happenings.Subscribe(entities.EventTriggered, func(happening entities.Happening) {
+		scoring.Record(happening.Event.ID)
+		switch happening.Event.ID {
+		case "desk-pushed":
+			if transition(&desk, "revealed") {
+				fmt.Println("A stack of dirty plates slides into view.\n\n(stack of plates can now be found in the room)")
+			} else {
+				fmt.Println("The plates you already found rattle, but nothing else turns up.")
+			}
+		case "student-woken":
+			transition(&sofa, "awake")
+		case caffeinated.ID:
+			vars.SetBool("caffeinated", true)
+		}
+	})
+
+	screenCleared := false
 
-		if player.CurrentEntity != nil && player.CurrentEntity.Name == "desk" {
-			firstPlate.Hidden = false
-			secondPlate.Hidden = false
-			thirdPlate.Hidden = false
-			fourthPlate.Hidden = false
-			fifthPlate.Hidden = false
-			sixthPlate.Hidden = false
-			desk.SetDescription("Despite the disarray, it's clear this desk sees frequent use, with just enough space left to get work done.")
+	clear := func() {
+		if !screenCleared {
+			clearScreen()
+			screenCleared = true
 		}
+	}
 
-		for _, validInteraction := range entities.ValidInteractions {
-			if validInteraction.Event.Description == "get-your-lanyard" && validInteraction.Event.Triggered {
-				lanyard.Hidden = false
-				rosie.SetDescription("Can I help with anything else?")
+	updateWorld := func(turnTaken bool) {
+		if turnTaken && !globalGame.GameOver {
+			entities.TickProcesses(processes, &player)
+		}
+
+		if kettle.Process.Completions > vars.Int("tea-brews") {
+			vars.SetInt("tea-brews", kettle.Process.Completions)
+			vars.SetInt("tea-brewed-at", globalGame.Turn)
+			if tea.Hidden {
+				tea.Hidden = false
+				fmt.Println("(tea can now be found in the room)")
+			} else if _, given := rosie.Inventory[tea.Name]; !given {
+				fmt.Println("You top up your mug with fresh tea.")
 			}
+			tea.Charges = 3
+			tea.SetState("hot")
+			transition(&kettle, "boiled")
 		}
 
-		dishwasherLoaded := true
-		for _, validInteraction := range entities.ValidInteractions {
-			if strings.HasSuffix(validInteraction.ItemName, "plate") && !validInteraction.Event.Triggered {
-				dishwasherLoaded = false
-				break
+		if vars.Int("tea-brews") > 0 && !tea.Hidden && tea.GetState() == "hot" && globalGame.Turn-vars.Int("tea-brewed-at") >= vars.Int("tea-cools-after") {
+			if _, given := rosie.Inventory[tea.Name]; !given {
+				tea.SetState("cold")
+				if _, carried := player.Inventory[tea.Name]; carried || player.CurrentRoom.Items[tea.Name] == &tea {
+					fmt.Println("Your tea has gone cold. Boil the kettle again to make a fresh one.")
+				}
 			}
 		}
 
-		if !dishwasherChallengeWon.Triggered {
-			if dishwasherLoaded {
-				player.TriggerEvent(dishwasherChallengeWon)
-				alan.SetDescription("Ah, so you've managed to load the dishwasher! Splendid work — consider this challenge complete.\nI could have done it myself instead of writing that clever recursive function, but where's the fun in that?\nAfter all, they pay me for my intellect, not for doing the heavy lifting!\nBut I digress. You're free to proceed to the terminal room and speak with Dan for your final challenge.\nYou're doing an excellent job; keep it up!")
-				dan.Hidden = false
-				terminal.Hidden = false
+		if entities.Index.HasTriggered("get-your-lanyard") {
+			if rosie.GetState() != "refreshed" {
+				rosie.Distract(3)
 			}
+			rosie.SetState("refreshed")
 		}
 
-		if _, ok := player.Inventory["abandoned-lanyard"]; ok {
+		if entities.Index.HasTriggered("dishwasher-switched-on") && !dishwasher.Process.Running && !dishwasherChallengeWon.Triggered {
+			dishwasher.Process.Start()
+		}
+
+		if dishwasherChallengeWon.Triggered {
+			for _, plate := range plates {
+				plate.SetState("clean")
+			}
+			terminal.Hidden = false
+		}
+
+		if turnTaken && !globalGame.GameOver {
+			entities.TickRoutines(routines, &player)
+			rosie.PassTurn()
+			player.TriggerTimedEvents(timedEvents, globalGame.CurrentTime)
+		}
+
+		if rosie.IsGrumpy() && !grumpyRosie.Triggered {
 			player.TriggerEvent(grumpyRosie)
-			globalGame.GameOver = true
 		}
+	}
 
-		if globalGame.GameOver {
-			fmt.Println("Thank you for playing!")
-			break
+	saveProfile := func() {
+		if !profileLoaded {
+			return
 		}
+		if err := playerProfile.Save(); err != nil {
+			fmt.Println("Could not save your profile:", err)
+		}
+	}
 
-		if !introductionShown {
-			clearScreen()
-			fmt.Println(introduction)
-			introductionShown = true
+	entities.TrackAchievements(entities.Happenings, achievements, func(achievement *entities.Achievement) bool {
+		if !playerProfile.Unlock(achievement.ID) {
+			return false
 		}
+		saveProfile()
+		return true
+	})
 
-		fmt.Print("Enter command: ")
+	defineAlias := func(input string) {
+		name, body, err := commands.ParseAlias(input)
+		if err == nil {
+			err = commands.DefineAlias(playerProfile.Aliases, name, body)
+		}
+		if err != nil {
+			fmt.Println("Could not define alias:", err)
+			return
+		}
+		saveProfile()
+		fmt.Printf("Defined alias %s = %s\n", name, body)
+	}
 
-		if scanner.Scan() {
-			input := scanner.Text()
-			input = strings.TrimSpace(input)
-			input = strings.ToLower(input)
+	execute := func(input string) bool {
+		if input == "exit" {
+			clear()
+			globalGame.GameOver = true
+			return false
+		}
 
-			if input == "exit" {
-				clearScreen()
-				fmt.Println("Thank you for playing!")
-				break
-			}
+		meta := commands.IsMeta(input)
 
-			if isAttemptingPassword {
-				if remainingPasswordAttempts == 1 && input != computerPassword {
-					clearScreen()
-					fmt.Println("Alan's computer is locked, halting your progress in the challenge. To top it off, you've made Rosie grumpy, as she'll now have to take the computer to IT.\n\nThank you for playing!")
-					break
-				}
-				if input == computerPassword {
-					clearScreen()
-					player.TriggerEvent(unlockComputer)
-					computer.SetDescription("function completeTask(pile)\n   if pile == 0:\n      return 'Task Complete'\n   else:\n      completeTask(pile - 1)\n")
-					alan.SetDescription("You've cracked the password! Impressive work... You should now see an open file containing a recursive function.\n\nFollow its instructions carefully, and you'll be one step closer to victory!\nBut, a word of caution: the task ahead is, well, a bit more hands-on than you might expect...")
-					isAttemptingPassword = false
-					desk.Hidden = false
-					dishwasher.Hidden = false
-				} else if input == "leave" {
-					isAttemptingPassword = false
-				} else {
-					remainingPasswordAttempts--
-					clearScreen()
-					fmt.Printf("Incorrect password. Try again, or type 'leave' to stop entering the password.\n\nRemaining attempts: %d\n\n", remainingPasswordAttempts)
-					computer.SetDescription(fmt.Sprintf("Alan's computer. You need the password to get in.\nRemaining attempts: %d.\nType 'leave' to stop entering the password.\n\nEnter the password:\n", remainingPasswordAttempts))
-					continue
+		if isAttemptingPassword && !meta {
+			if vars.Int("password-attempts-left") == 1 && input != computerPassword {
+				clear()
+				player.TriggerEvent(computerLocked)
+				return false
+			}
+			if input == computerPassword {
+				clear()
+				player.TriggerEvent(unlockComputer)
+				transition(&computer, "unlocked")
+				isAttemptingPassword = false
+				return true
+			} else if input == "leave" {
+				isAttemptingPassword = false
+			} else {
+				vars.AddInt("password-attempts-left", -1)
+				vars.AddInt("wrong-passwords", 1)
+				scoring.Penalise("wrong-password")
+				clear()
+				fmt.Printf("Incorrect password. Try again, or type 'leave' to stop entering the password.\n\nRemaining attempts: %d\n\n", vars.Int("password-attempts-left"))
+				if _, present := player.CurrentRoom.Entities[rosie.Name]; present {
+					rosie.ChangeMood(-5)
 				}
+				return false
 			}
+		}
 
-			if isAttemptingTerminal {
-				if input == "leave" {
-					isAttemptingTerminal = false
-					clearScreen()
-					player.Leave()
-					continue
-				}
+		if isAttemptingTerminal && !meta {
+			if input == "leave" {
+				isAttemptingTerminal = false
+				clear()
+				return player.Leave()
+			}
 
-				if !IsFirstCommand {
-					if input == "cd /secret-files" {
-						clearScreen()
-						fmt.Println("The terminal displays:\n\n/secret-files/\n\nIt looks like you are on the right track.\nEnter the final command to win the game!\n\nType 'leave' to stop entering commands on the terminal.")
-						IsFirstCommand = true
-						terminal.SetDescription("A sleek terminal sits on the desk, its screen displaying lines of code and system commands.\nThe keyboard, slightly worn, hints at frequent use.\nThis device is essential for executing tasks and accessing the building's network.\n\nEnter your commands below or type 'leave' to exit the terminal.\n\nThe terminal displays:\n\n/secret-files/\n\nIt looks like you are on the right track.\nEnter the final command to win the game!\n\nType 'leave' to stop entering commands on the terminal.\n")
-						continue
-					} else {
-						clearScreen()
-						fmt.Printf("The terminal displays:\n\nbash: %s: command not found\n\nType 'leave' to stop entering commands on the terminal\n\n", input)
-						continue
-					}
-				} else {
-					if input == "cat unlock-exits-instructions.txt" {
-						clearScreen()
-						fmt.Println("As you execute the final command, the terminal whirs to life, and the screen fills with a flurry of colorful text.\nThe words 'Victory Achieved!' flash across the display, illuminating your face with a soft glow.\nYou feel a rush of adrenaline as the file containing the instructions to unlock the exits appears before you.\nFollowing the instructions carefully, you swiftly input the necessary commands, and with a satisfying beep, the locks on the exits click open.\nThe room is filled with the sound of machinery grinding to a halt as the doors swing wide.")
-						globalGame.GameOver = true
-						continue
-					} else {
-						clearScreen()
-						fmt.Printf("The terminal displays:\n\nbash: %s: command not found\n\nType 'leave' to stop entering commands on the terminal\n\n", input)
-						continue
-					}
+			if !vars.Bool("in-secret-files") {
+				if input == "cd /secret-files" {
+					clear()
+					fmt.Println("The terminal displays:\n\n/secret-files/\n\nIt looks like you are on the right track.\nEnter the final command to win the game!\n\nType 'leave' to stop entering commands on the terminal.")
+					vars.SetBool("in-secret-files", true)
+					return true
 				}
+			} else if input == "cat unlock-exits-instructions.txt" {
+				clear()
+				player.TriggerEvent(exitsUnlocked)
+				return true
 			}
+			clear()
+			fmt.Printf("The terminal displays:\n\nbash: %s: command not found\n\nType 'leave' to stop entering commands on the terminal\n\n", input)
+			return false
+		}
 
-			parts := strings.Fields(input)
-			if len(parts) == 0 {
-				continue
+		if isTalking && player.CurrentEntity == nil {
+			isTalking = false
+		}
+
+		if isTalking {
+			if input == "bye" {
+				isTalking = false
+				clear()
+				fmt.Printf("You stop talking to %s.\n", player.CurrentEntity.Name)
+				return true
+			}
+			if _, err := strconv.Atoi(input); err == nil {
+				clear()
+				return player.ChooseTopic(input)
 			}
+			isTalking = false
+		}
 
-			command := (parts[0])
-			args := parts[1:]
-
-			switch command {
-			case "commands":
-				clearScreen()
-				showCommands()
-			case "look":
-				clearScreen()
-				player.ShowRoom()
-			case "take":
-				clearScreen()
-				if len(args) > 0 {
-					player.Take(args[0])
-				} else {
-					fmt.Println("Specify an item to take.")
-				}
-			case "drop":
-				clearScreen()
-				if len(args) > 0 {
-					player.Drop(args[0])
-				} else {
-					fmt.Println("Specify an item to drop.")
-				}
-			case "inventory":
-				clearScreen()
-				player.ShowInventory()
-			case "approach":
-				clearScreen()
-				if len(args) > 0 {
-					player.Approach(args[0])
-
-					if !unlockComputer.Triggered {
-						if player.CurrentEntity != nil && player.CurrentEntity.Name == "computer" {
-							isAttemptingPassword = true
-						}
-					}
-					if player.CurrentEntity != nil && player.CurrentEntity.Name == "terminal" {
-						isAttemptingTerminal = true
-					}
-
-				} else {
-					fmt.Println("Specify an entity to approach.")
+		if pending != nil {
+			if choice, ok := entities.ChooseCandidate(input, pending.candidates); ok {
+				input = strings.TrimSpace(pending.command + " " + choice + " " + pending.rest)
+			}
+			pending = nil
+		}
+
+		parts := strings.Fields(input)
+		if len(parts) == 0 {
+			return false
+		}
+
+		command := (parts[0])
+		args := parts[1:]
+		if command == "switch" && len(args) > 0 && args[0] == "on" {
+			command = "switch-on"
+			args = args[1:]
+		}
+
+		switch command {
+		case "commands":
+			clear()
+			showCommands()
+		case "look":
+			clear()
+			player.ShowRoom()
+		case "take":
+			clear()
+			if len(args) == 0 {
+				fmt.Println("Specify an item to take.")
+				return false
+			}
+			name, ok := resolve("take", args[0], "", entities.RoomItems)
+			return ok && player.Take(name)
+		case "drop":
+			clear()
+			if len(args) == 0 {
+				fmt.Println("Specify an item to drop.")
+				return false
+			}
+			name, ok := resolve("drop", args[0], "", entities.InventoryItems)
+			return ok && player.Drop(name)
+		case "inventory":
+			clear()
+			player.ShowInventory()
+		case "examine":
+			clear()
+			if len(args) == 0 {
+				fmt.Println("Specify something to examine.")
+				return false
+			}
+			name, ok := resolve("examine", args[0], "", entities.RoomItems|entities.RoomEntities|entities.InventoryItems)
+			return ok && player.Examine(name)
+		case "approach":
+			clear()
+			if len(args) == 0 {
+				fmt.Println("Specify an entity to approach.")
+				return false
+			}
+			name, ok := resolve("approach", args[0], "", entities.RoomEntities)
+			if !ok || !player.Approach(name) {
+				return false
+			}
+
+			if !unlockComputer.Triggered {
+				if player.CurrentEntity.Name == "computer" {
+					isAttemptingPassword = true
 				}
-			case "use":
-				clearScreen()
-				if len(args) > 0 {
-					if player.CurrentEntity == nil {
-						player.Use(args[0], "unspecified_entity")
-					} else {
-						player.Use(args[0], player.CurrentEntity.Name)
-					}
-				} else {
-					fmt.Println("Specify an item to use.")
+			}
+			if player.CurrentEntity.Name == "terminal" {
+				isAttemptingTerminal = true
+			}
+		case "use":
+			clear()
+			if len(args) == 0 {
+				fmt.Println("Specify an item to use.")
+				return false
+			}
+			name, ok := resolve("use", args[0], "", entities.InventoryItems)
+			if !ok {
+				return false
+			}
+			if player.CurrentEntity == nil {
+				return player.Use(name, "unspecified_entity")
+			}
+			return player.Use(name, player.CurrentEntity.Name)
+		case "talk":
+			clear()
+			if len(args) > 0 && args[0] == "to" {
+				args = args[1:]
+			}
+			if len(args) == 0 {
+				fmt.Println("Specify someone to talk to.")
+				return false
+			}
+			name, ok := resolve("talk to", args[0], "", entities.RoomEntities)
+			if !ok || !player.Talk(name) {
+				return false
+			}
+			isTalking = true
+		case "ask":
+			clear()
+			if len(args) > 1 && args[1] == "about" {
+				args = append(args[:1], args[2:]...)
+			}
+			if len(args) < 2 {
+				fmt.Println("Specify who to ask and what about (e.g., ask alan about password).")
+				return false
+			}
+			name, ok := resolve("ask", args[0], "about "+args[1], entities.RoomEntities)
+			return ok && player.Ask(name, args[1])
+		case "give":
+			clear()
+			if len(args) > 1 && args[1] == "to" {
+				args = append(args[:1], args[2:]...)
+			}
+			if len(args) < 2 {
+				fmt.Println("Specify an item and who to give it to (e.g., give tea to rosie).")
+				return false
+			}
+			itemName, ok := resolve("give", args[0], "to "+args[1], entities.InventoryItems)
+			if !ok {
+				return false
+			}
+			name, ok := resolve("give "+itemName+" to", args[1], "", entities.RoomEntities)
+			return ok && player.Give(itemName, name)
+		case "drink":
+			clear()
+			if len(args) == 0 {
+				fmt.Println("Specify an item to drink.")
+				return false
+			}
+			name, ok := resolve("drink", args[0], "", entities.InventoryItems)
+			return ok && player.Act("drink", name)
+		case "read":
+			clear()
+			if len(args) == 0 {
+				fmt.Println("Specify something to read.")
+				return false
+			}
+			name, ok := resolve("read", args[0], "", entities.InventoryItems|entities.RoomEntities)
+			if !ok {
+				return false
+			}
+			if _, carried := player.FindInventoryItem(name); carried {
+				return player.Act("read", name)
+			}
+			return player.Perform("read", name)
+		case "insert":
+			clear()
+			if len(args) == 0 {
+				fmt.Println("Specify an item to insert.")
+				return false
+			}
+			name, ok := resolve("insert", args[0], "", entities.InventoryItems)
+			if !ok {
+				return false
+			}
+			if player.CurrentEntity == nil {
+				fmt.Println("Approach something to insert an item into it.")
+				return false
+			}
+			return player.Use(name, player.CurrentEntity.Name)
+		case "combine":
+			clear()
+			if len(args) > 1 && args[1] == "with" {
+				args = append(args[:1], args[2:]...)
+			}
+			if len(args) < 2 {
+				fmt.Println("Specify two items to combine (e.g., combine torn-note with note-scrap).")
+				return false
+			}
+			first, ok := resolve("combine", args[0], "with "+args[1], entities.InventoryItems)
+			if !ok {
+				return false
+			}
+			second, ok := resolve("combine "+first+" with", args[1], "", entities.InventoryItems)
+			return ok && player.Combine(first, second)
+		case "wait":
+			clear()
+			fmt.Println("You wait for a moment.")
+		case "leave":
+			clear()
+			return player.Leave()
+		case "move":
+			clear()
+			_, hasLanyard := player.Inventory["lanyard"]
+			_, hasAbandonedLanyard := player.Inventory["abandoned-lanyard"]
+			if !hasLanyard && !hasAbandonedLanyard {
+				fmt.Println("Doors are shut for you if you don't have a lanyard.")
+				return false
+			}
+			if len(args) == 0 {
+				fmt.Println("Specify a direction to move (e.g., north).")
+				return false
+			}
+			return player.Move(args[0])
+		case "map":
+			clear()
+			player.ShowMap()
+		case "achievements":
+			clear()
+			for _, achievement := range achievements {
+				mark := " "
+				if playerProfile.HasAchievement(achievement.ID) {
+					mark = "x"
 				}
-			case "leave":
-				clearScreen()
-				player.Leave()
-			case "move":
-				clearScreen()
-				if _, ok := player.Inventory["lanyard"]; ok {
-					if len(args) > 0 {
-						player.Move(args[0])
-					} else {
-						fmt.Println("Specify a direction to move (e.g., north).")
-					}
-				} else {
-					fmt.Println("Doors are shut for you if you don't have a lanyard.")
+				fmt.Printf("[%s] %s - %s\n", mark, achievement.Title, achievement.Description)
+			}
+		case "hint":
+			clear()
+			if hints.Remaining() <= 0 {
+				fmt.Println("You've used all your hints for this game. You're on your own now!")
+				return false
+			}
+			hint, ok := hints.Next()
+			if !ok {
+				fmt.Println("You don't need a hint right now.")
+				return false
+			}
+			scoring.Penalise("hint")
+			fmt.Printf("%s\n\n(Hints left: %d)\n", hint, hints.Remaining())
+		case "score":
+			clear()
+			fmt.Printf("Score: %d\n%s\n", scoring.Total(), scoring.Breakdown())
+		case "aliases":
+			clear()
+			showAliases(playerProfile.Aliases)
+		case "unalias":
+			clear()
+			if len(args) == 0 {
+				fmt.Println("Specify an alias to remove.")
+				return false
+			}
+			if _, ok := playerProfile.Aliases[args[0]]; !ok {
+				fmt.Printf("There is no alias called %s.\n", args[0])
+				return false
+			}
+			delete(playerProfile.Aliases, args[0])
+			saveProfile()
+			fmt.Printf("Removed alias %s.\n", args[0])
+		case "debug":
+			clear()
+			if !*debug {
+				fmt.Println("Unknown command:", command)
+				return false
+			}
+			switch {
+			case len(args) > 0 && args[0] == "vars":
+				fmt.Println(vars.Dump())
+			case len(args) > 0 && args[0] == "states":
+				for _, entity := range stateful {
+					fmt.Println(entities.DescribeMachine(entity))
 				}
-			case "map":
-				clearScreen()
-				player.ShowMap()
-			case computerPassword:
-				continue
 			default:
-				clearScreen()
+				fmt.Println("Usage: debug vars | debug states")
+				return false
+			}
+		case computerPassword:
+			return true
+		default:
+			clear()
+			if !entities.Index.HasVerb(command) {
 				fmt.Println("Unknown command:", command)
+				return false
+			}
+			if len(args) == 0 {
+				fmt.Printf("Specify something to %s.\n", command)
+				return false
+			}
+			name, ok := resolve(command, args[0], "", entities.RoomEntities)
+			return ok && player.Perform(command, name)
+		}
+		return true
+	}
+
+	for {
+		if globalGame.GameOver {
+			entities.Happenings.Publish(entities.Happening{Kind: entities.GameEnded, Room: player.CurrentRoom})
+			if endings.Reached != nil {
+				playerProfile.SeeEnding(endings.Reached.ID)
+			}
+			playerProfile.LastGame = vars
+			saveProfile()
+			showEpilogue(endings, playerProfile, scoring, vars.Int("commands-entered"))
+			fmt.Println("Thank you for playing!")
+			break
+		}
+
+		if !introductionShown {
+			clearScreen()
+			fmt.Println(introduction)
+			introductionShown = true
+		}
+
+		fmt.Println(globalGame.StatusLine())
+		editor.Private = isAttemptingPassword
+		line, err := editor.ReadLine("Enter command: ")
+		if err != nil {
+			globalGame.GameOver = true
+			continue
+		}
+		input := strings.ToLower(strings.TrimSpace(line))
+
+		screenCleared = false
+		clauses := []string{input}
+		if isAttemptingPassword || isAttemptingTerminal {
+			if input == "" {
+				continue
+			}
+		} else {
+			if input == "alias" || strings.HasPrefix(input, "alias ") {
+				clear()
+				defineAlias(input)
+				continue
+			}
+
+			clauses, err = commands.Expand(input, playerProfile.Aliases)
+			if err != nil {
+				clear()
+				fmt.Println(err)
+				continue
+			}
+		}
+		for i, clause := range clauses {
+			if len(clauses) > 1 {
+				clear()
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("> %s\n", clause)
+			}
+			vars.AddInt("commands-entered", 1)
+			ok := execute(clause)
+			turnTaken := false
+			if ok && !globalGame.GameOver {
+				verb := strings.Fields(clause)[0]
+				cost := globalGame.VerbCost(verb)
+				if verb == "move" && vars.Bool("caffeinated") {
+					cost = vars.Int("caffeinated-move-cost")
+				}
+				turnTaken = globalGame.Spend(cost) > 0
+			}
+			updateWorld(turnTaken)
+			if !ok || globalGame.GameOver {
+				break
 			}
 		}
 	}
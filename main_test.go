@@ -1,28 +1,33 @@
 package main
 
 import (
+	"academy-adventure-game/commands"
 	"academy-adventure-game/describable"
 	"academy-adventure-game/entities"
+	"academy-adventure-game/globalGame"
+	"academy-adventure-game/profile"
+	"academy-adventure-game/prompt"
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
 func setUpValidInteractions() {
-	entities.ValidInteractions = []*entities.Interaction{
-		{
+	entities.Index = entities.NewRegistry(
+		&entities.Interaction{
 			ItemName:   "key",
 			EntityName: "door",
-			Event:      &entities.Event{Description: "unlock_door", Outcome: "The door unlocks with a loud click.\n", Triggered: false},
+			Event:      &entities.Event{ID: "unlock_door", Outcome: "The door unlocks with a loud click.\n", Triggered: false},
 		},
-		{
+		&entities.Interaction{
 			ItemName:   "water",
 			EntityName: "plant",
-			Event:      &entities.Event{Description: "water_plant", Outcome: "The plant looks healthier after being watered.\n", Triggered: false},
+			Event:      &entities.Event{ID: "water_plant", Outcome: "The plant looks healthier after being watered.\n", Triggered: false},
 		},
-	}
+	)
 }
 
 func TestPlayerMovement(t *testing.T) {
@@ -696,7 +701,7 @@ func TestValidUseItem(t *testing.T) {
 	player.Use("key", "door")
 
 	//Assert
-	if !entities.ValidInteractions[0].Event.Triggered {
+	if !entities.Index.HasTriggered("unlock_door") {
 		t.Errorf("Expected event to be true for triggered, got false")
 	}
 	if _, ok := player.Inventory["key"]; ok {
@@ -727,7 +732,7 @@ func TestInvalidUseItem(t *testing.T) {
 	player.Use("key", "plant")
 
 	//Assert
-	for _, validInteraction := range entities.ValidInteractions {
+	for _, validInteraction := range entities.Index.Interactions() {
 		if validInteraction.Event.Triggered {
 			t.Errorf("Expected event to be false for triggered, got true")
 		}
@@ -750,7 +755,7 @@ func TestUseAbsentItem(t *testing.T) {
 	player.Use("key", "door")
 
 	//Assert
-	if entities.ValidInteractions[0].Event.Triggered {
+	if entities.Index.HasTriggered("unlock_door") {
 		t.Errorf("Expected event to be false for triggered, got true")
 	}
 }
@@ -771,7 +776,7 @@ func TestUseAbsentEntity(t *testing.T) {
 	player.Use("key", "door")
 
 	//Assert
-	if entities.ValidInteractions[0].Event.Triggered {
+	if entities.Index.HasTriggered("unlock_door") {
 		t.Errorf("Expected event to be false for triggered, got true")
 	}
 }
@@ -794,9 +799,1488 @@ func TestShowCommands(t *testing.T) {
 
 	// Assert
 	output := buf.String()
-	expectedOutput := fmt.Sprintln("-exit -> quits the game\n\n-commands -> shows the commands\n\n-look -> shows the content of the room.\n\n-approach <entity> -> to approach an entity\n\n-leave -> to leave an entity\n\n-inventory -> shows items in the inventory\n\n-take <item> -> to take an item into your inventory\n\n-drop <item> -> to drop an item from your inventory and move it to the current room\n\n-use <item> -> to make use of a certain item when you approach an entity\n\n-move <direction> -> to move to a different room\n\n-map -> shows the directions you can take")
+	expectedOutput := fmt.Sprintln("-exit -> quits the game\n\n-commands -> shows the commands\n\n-look -> shows the content of the room.\n\n-approach <entity> -> to approach an entity\n\n-talk to <entity> -> starts a conversation with someone\n\n-ask <entity> about <topic> -> asks someone about a topic\n\n-give <item> to <entity> -> hands an item to someone\n\n-leave -> to leave an entity\n\n-examine <thing> -> takes a closer look at an item or entity\n\n-inventory -> shows items in the inventory\n\n-take <item> -> to take an item into your inventory\n\n-drop <item> -> to drop an item from your inventory and move it to the current room\n\n-use <item> -> to make use of a certain item when you approach an entity\n\n-drink <item> -> drinks from an item in your inventory\n\n-read <thing> -> reads what is written on an item or entity\n\n-push <entity> -> gives something a shove\n\n-wake <entity> -> wakes someone up\n\n-pet <entity> -> strokes an animal\n\n-switch on <entity> -> turns on a machine\n\n-insert <item> -> puts an item into the entity you have approached\n\n-combine <item> with <item> -> makes something new out of two items\n\n-wait -> lets a little time pass\n\n-move <direction> -> to move to a different room\n\n-map -> shows the directions you can take\n\n-score -> shows your score so far\n\n-hint -> gives you a nudge in the right direction\n\n-achievements -> shows the achievements you have unlocked\n\n-alias <name> = <commands> -> defines a shortcut for one or more commands\n\n-unalias <name> -> removes a shortcut\n\n-aliases -> shows your shortcuts")
 
 	if output != expectedOutput {
 		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
 	}
 }
+
+func TestResolveAmbiguousName(t *testing.T) {
+	//Arrange
+	room := entities.Room{Items: make(map[string]*entities.Item)}
+	lanyard := entities.Item{Name: "lanyard"}
+	abandonedLanyard := entities.Item{Name: "abandoned-lanyard"}
+	room.Items[lanyard.Name] = &lanyard
+	room.Items[abandonedLanyard.Name] = &abandonedLanyard
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item)}
+
+	//Act
+	name, candidates := player.Resolve("lanyard", entities.RoomItems)
+
+	//Assert
+	if name != "" {
+		t.Errorf("Expected no resolved name, got %s", name)
+	}
+	expectedPrompt := "Which do you mean: lanyard or abandoned-lanyard?"
+	if prompt := disambiguationPrompt(candidates); prompt != expectedPrompt {
+		t.Errorf("Expected prompt:\n%s\nGot:\n%s", expectedPrompt, prompt)
+	}
+}
+
+func TestResolvePartialName(t *testing.T) {
+	//Arrange
+	room := entities.Room{Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	plate := entities.Item{Name: "first-plate"}
+	hiddenPlate := entities.Item{Name: "second-plate", Hidden: true}
+	room.Items[plate.Name] = &plate
+	room.Items[hiddenPlate.Name] = &hiddenPlate
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item)}
+
+	//Act
+	name, candidates := player.Resolve("fir", entities.RoomItems)
+
+	//Assert
+	if name != plate.Name || candidates != nil {
+		t.Errorf("Expected %s to be resolved, got %s with candidates %v", plate.Name, name, candidates)
+	}
+}
+
+func TestChooseCandidate(t *testing.T) {
+	//Arrange
+	candidates := []string{"lanyard", "abandoned-lanyard"}
+
+	//Act
+	exact, exactOk := entities.ChooseCandidate("lanyard", candidates)
+	partial, partialOk := entities.ChooseCandidate("abandoned", candidates)
+	_, unknownOk := entities.ChooseCandidate("look", candidates)
+
+	//Assert
+	if !exactOk || exact != "lanyard" {
+		t.Errorf("Expected lanyard to be chosen, got %s", exact)
+	}
+	if !partialOk || partial != "abandoned-lanyard" {
+		t.Errorf("Expected abandoned-lanyard to be chosen, got %s", partial)
+	}
+	if unknownOk {
+		t.Errorf("Expected an unrelated answer not to choose a candidate")
+	}
+}
+
+func TestTakeItemByAlias(t *testing.T) {
+	//Arrange
+	room := entities.Room{Items: make(map[string]*entities.Item)}
+	tea := entities.Item{Name: "tea", Weight: 2, Aliases: []string{"mug", "cup"}}
+	room.Items[tea.Name] = &tea
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item), AvailableWeight: 30}
+
+	//Act
+	player.Take("mug")
+
+	//Assert
+	if _, ok := player.Inventory[tea.Name]; !ok {
+		t.Errorf("Expected %s to be taken by its alias", tea.Name)
+	}
+	if _, ok := room.Items[tea.Name]; ok {
+		t.Errorf("Expected %s to be removed from the room", tea.Name)
+	}
+}
+
+func TestApproachEntityByAlias(t *testing.T) {
+	//Arrange
+	room := entities.Room{Entities: make(map[string]*entities.Entity)}
+	computer := entities.Entity{Name: "computer", Aliases: []string{"pc", "laptop"}}
+	room.Entities[computer.Name] = &computer
+	player := entities.Player{CurrentRoom: &room}
+
+	//Act
+	player.Approach("laptop")
+
+	//Assert
+	if player.CurrentEntity == nil || player.CurrentEntity.Name != computer.Name {
+		t.Errorf("Expected player to approach %s by its alias", computer.Name)
+	}
+}
+
+func TestPlayerMovementDirectionAlias(t *testing.T) {
+	//Arrange
+	room1 := entities.Room{Name: "Room 1", Exits: make(map[string]*entities.Room)}
+	room2 := entities.Room{Name: "Room 2", Exits: make(map[string]*entities.Room)}
+	room1.Exits["north"] = &room2
+	room2.Exits["south"] = &room1
+	entities.DirectionAliases = map[string]string{"n": "north"}
+	player := entities.Player{CurrentRoom: &room1}
+
+	//Act
+	player.Move("n")
+
+	//Assert
+	if player.CurrentRoom.Name != "Room 2" {
+		t.Errorf("Expected Room 2, got %s", player.CurrentRoom.Name)
+	}
+}
+
+func TestFindItemSharedAliasIsDeterministic(t *testing.T) {
+	//Arrange
+	room := entities.Room{Items: make(map[string]*entities.Item)}
+	for _, name := range []string{"tea", "coffee", "juice", "water", "milk"} {
+		room.Items[name] = &entities.Item{Name: name, Aliases: []string{"drink"}}
+	}
+
+	//Act
+	found := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		item, _ := room.FindItem("drink")
+		found[item.Name] = true
+	}
+
+	//Assert
+	if len(found) != 1 || !found["coffee"] {
+		t.Errorf("Expected the shared alias to always find coffee, got %v", found)
+	}
+}
+
+func TestSplitCommands(t *testing.T) {
+	//Arrange
+	input := "take tea, move south;look then take first-plate and use first-plate"
+
+	//Act
+	clauses := commands.Split(input)
+
+	//Assert
+	expected := []string{"take tea", "move south", "look", "take first-plate", "use first-plate"}
+	if strings.Join(clauses, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected clauses %v, got %v", expected, clauses)
+	}
+}
+
+func TestSplitCommandsSingleClause(t *testing.T) {
+	//Act
+	clauses := commands.Split("cd  /secret-files")
+
+	//Assert
+	if len(clauses) != 1 || clauses[0] != "cd /secret-files" {
+		t.Errorf("Expected a single clause, got %v", clauses)
+	}
+	if len(commands.Split("  ")) != 0 {
+		t.Errorf("Expected no clauses for blank input")
+	}
+}
+
+func TestTakeReportsFailure(t *testing.T) {
+	//Arrange
+	room := entities.Room{Items: make(map[string]*entities.Item)}
+	item := entities.Item{Name: "Item", Weight: 10}
+	room.Items[item.Name] = &item
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item), AvailableWeight: 5}
+
+	//Act
+	ok := player.Take(item.Name)
+
+	//Assert
+	if ok {
+		t.Errorf("Expected taking an item over the weight limit to fail")
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	//Arrange
+	aliases := make(map[string]string)
+	commands.DefineAlias(aliases, "load", "use first-plate")
+	commands.DefineAlias(aliases, "fetch", "take tea, load")
+
+	//Act
+	clauses, err := commands.Expand("fetch then look", aliases)
+
+	//Assert
+	expected := []string{"take tea", "use first-plate", "look"}
+	if err != nil || strings.Join(clauses, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected clauses %v, got %v (%v)", expected, clauses, err)
+	}
+}
+
+func TestDefineRecursiveAlias(t *testing.T) {
+	//Arrange
+	aliases := make(map[string]string)
+	commands.DefineAlias(aliases, "first", "look, second")
+
+	//Act
+	err := commands.DefineAlias(aliases, "second", "first")
+
+	//Assert
+	if err == nil {
+		t.Errorf("Expected a recursive alias to be rejected")
+	}
+	if _, ok := aliases["second"]; ok {
+		t.Errorf("Expected the recursive alias not to be stored")
+	}
+}
+
+func TestDefineBuiltinAlias(t *testing.T) {
+	//Arrange
+	aliases := make(map[string]string)
+	name, body, _ := commands.ParseAlias("alias look = map")
+
+	//Act
+	err := commands.DefineAlias(aliases, name, body)
+
+	//Assert
+	if err == nil {
+		t.Errorf("Expected an alias shadowing a command to be rejected")
+	}
+}
+
+func TestProfileAliasesPersist(t *testing.T) {
+	//Arrange
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	saved, _ := profile.Load("tester")
+	saved.Aliases["load"] = "use first-plate"
+
+	//Act
+	err := saved.Save()
+	loaded, loadErr := profile.Load("tester")
+
+	//Assert
+	if err != nil || loadErr != nil {
+		t.Fatalf("Expected profile to save and load, got %v and %v", err, loadErr)
+	}
+	if loaded.Aliases["load"] != "use first-plate" {
+		t.Errorf("Expected alias to persist, got %v", loaded.Aliases)
+	}
+}
+
+func TestProfileSavesLastGameVariables(t *testing.T) {
+	//Arrange
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	saved, _ := profile.Load("tester")
+	vars := globalGame.NewVariables()
+	vars.SetBool("in-secret-files", true)
+	vars.SetInt("wrong-passwords", 2)
+	vars.SetString("ending", "escaped")
+	saved.LastGame = vars
+
+	//Act
+	err := saved.Save()
+	loaded, loadErr := profile.Load("tester")
+
+	//Assert
+	if err != nil || loadErr != nil || loaded.LastGame == nil {
+		t.Fatalf("Expected the last game's variables to save and load, got %v and %v", err, loadErr)
+	}
+	if loaded.LastGame.Dump() != vars.Dump() {
+		t.Errorf("Expected variables:\n%s\nGot:\n%s", vars.Dump(), loaded.LastGame.Dump())
+	}
+}
+
+func TestProfileRejectsNamesOutsideConfigDir(t *testing.T) {
+	//Arrange
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	//Act
+	_, pathErr := profile.Path("../escaped")
+	_, historyErr := profile.HistoryPath("nested/name")
+	_, loadErr := profile.Load("..")
+
+	//Assert
+	if pathErr == nil || historyErr == nil || loadErr == nil {
+		t.Errorf("Expected profile names with path separators to be rejected")
+	}
+}
+
+func TestIsMeta(t *testing.T) {
+	//Act
+	hint := commands.IsMeta("hint")
+	debug := commands.IsMeta("debug vars")
+	password := commands.IsMeta("iiwsccrtc")
+	terminal := commands.IsMeta("cd /secret-files")
+
+	//Assert
+	if !hint || !debug || password || terminal {
+		t.Errorf("Expected only meta commands to be recognised")
+	}
+}
+
+func TestPruneAliasesShadowingCommands(t *testing.T) {
+	//Arrange
+	aliases := map[string]string{"debug": "look", "load": "use first-plate", "bye": "exit"}
+
+	//Act
+	pruned := commands.PruneAliases(aliases)
+
+	//Assert
+	if strings.Join(pruned, ",") != "bye,debug" {
+		t.Errorf("Expected bye and debug to be pruned, got %v", pruned)
+	}
+	if len(aliases) != 1 || aliases["load"] != "use first-plate" {
+		t.Errorf("Expected only the load alias to remain, got %v", aliases)
+	}
+}
+
+func TestCompletionCandidates(t *testing.T) {
+	//Arrange
+	room1 := entities.Room{Name: "Room 1", Exits: make(map[string]*entities.Room), Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	room2 := entities.Room{Name: "Room 2", Exits: make(map[string]*entities.Room)}
+	room1.Exits["north"] = &room2
+	tea := entities.Item{Name: "tea"}
+	cd := entities.Item{Name: "cd", Hidden: true}
+	rosie := entities.Entity{Name: "rosie"}
+	room1.Items[tea.Name] = &tea
+	room1.Items[cd.Name] = &cd
+	room1.Entities[rosie.Name] = &rosie
+	lanyard := entities.Item{Name: "lanyard"}
+	player := entities.Player{CurrentRoom: &room1, Inventory: map[string]*entities.Item{lanyard.Name: &lanyard}}
+	aliases := map[string]string{"load": "use first-plate"}
+
+	//Act
+	verbs := completionCandidates(&player, aliases, "lo")
+	items := completionCandidates(&player, aliases, "look, take t")
+	directions := completionCandidates(&player, aliases, "take tea then move ")
+	inventory := completionCandidates(&player, aliases, "drop ")
+	approachable := completionCandidates(&player, aliases, "approach r")
+
+	//Assert
+	if !strings.Contains(strings.Join(verbs, " "), "load") || !strings.Contains(strings.Join(verbs, " "), "look") {
+		t.Errorf("Expected commands and aliases, got %v", verbs)
+	}
+	if strings.Join(items, " ") != "tea" {
+		t.Errorf("Expected visible room items, got %v", items)
+	}
+	if strings.Join(directions, " ") != "north" {
+		t.Errorf("Expected exits, got %v", directions)
+	}
+	if strings.Join(inventory, " ") != "lanyard" {
+		t.Errorf("Expected inventory items, got %v", inventory)
+	}
+	if strings.Join(approachable, " ") != "rosie" {
+		t.Errorf("Expected entities, got %v", approachable)
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	//Act
+	prefix := prompt.CommonPrefix([]string{"first-plate", "fifth-plate", "fourth-plate"})
+
+	//Assert
+	if prefix != "f" {
+		t.Errorf("Expected f, got %s", prefix)
+	}
+}
+
+func TestLoadHistory(t *testing.T) {
+	//Arrange
+	path := filepath.Join(t.TempDir(), "history")
+	os.WriteFile(path, []byte("look\n\ntake tea\n"), 0o644)
+
+	//Act
+	history := prompt.LoadHistory(path)
+
+	//Assert
+	if strings.Join(history, "|") != "look|take tea" {
+		t.Errorf("Expected history to be loaded without blank lines, got %v", history)
+	}
+}
+
+func TestShortAndLongDescriptions(t *testing.T) {
+	//Arrange
+	item := &entities.Item{Name: "Item", Description: "This is an item.", ShortDescription: "An item.", LongDescription: "This is a very detailed item."}
+	entity := &entities.Entity{Name: "Entity", Description: "This is an entity.", StateDescriptions: map[string]string{"broken": "This entity is broken."}}
+	var itemDescribable describable.Describable = item
+
+	//Act
+	describable.ChangeState(entity, "broken")
+
+	//Assert
+	if itemDescribable.GetShortDescription() != item.ShortDescription {
+		t.Errorf("Expected short description:\n%s\nGot:\n%s", item.ShortDescription, item.GetShortDescription())
+	}
+	if item.GetLongDescription() != item.LongDescription {
+		t.Errorf("Expected long description:\n%s\nGot:\n%s", item.LongDescription, item.GetLongDescription())
+	}
+	if entity.GetLongDescription() != "This entity is broken." {
+		t.Errorf("Expected state description, got:\n%s", entity.GetLongDescription())
+	}
+}
+
+func TestExamineItem(t *testing.T) {
+	//Arrange
+	room := entities.Room{Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	item := entities.Item{Name: "Item", Description: "This is an item.", LongDescription: "This is a very detailed item."}
+	room.Items[item.Name] = &item
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item)}
+
+	r, w, _ := os.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	original := os.Stdout
+	os.Stdout = w
+
+	//Act
+	ok := player.Examine(item.Name)
+
+	w.Close()
+	os.Stdout = original
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	//Assert
+	if !ok {
+		t.Errorf("Expected examining a visible item to succeed")
+	}
+	if output := buf.String(); output != item.LongDescription+"\n" {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", item.LongDescription, output)
+	}
+}
+
+func TestExamineHiddenEntity(t *testing.T) {
+	//Arrange
+	room := entities.Room{Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	entity := entities.Entity{Name: "Entity", Description: "This is an entity.", Hidden: true}
+	room.Entities[entity.Name] = &entity
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item)}
+
+	//Act
+	ok := player.Examine(entity.Name)
+
+	//Assert
+	if ok {
+		t.Errorf("Expected examining a hidden entity to fail")
+	}
+}
+
+func TestTalkShowsUnlockedTopics(t *testing.T) {
+	//Arrange
+	unlocked := &entities.Event{ID: "unlocked", Triggered: false}
+	room := entities.Room{Entities: make(map[string]*entities.Entity)}
+	npc := entities.Entity{Name: "npc", Topics: []*entities.Topic{
+		{Keyword: "weather", Prompt: "Nice weather?", Response: "Lovely."},
+		{Keyword: "secret", Prompt: "Any secrets?", Response: "Not telling.", Requires: unlocked},
+	}}
+	room.Entities[npc.Name] = &npc
+	player := entities.Player{CurrentRoom: &room}
+
+	r, w, _ := os.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	original := os.Stdout
+	os.Stdout = w
+
+	//Act
+	player.Talk("npc")
+
+	w.Close()
+	os.Stdout = original
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	//Assert
+	expectedOutput := "You talk to npc.\n\n1. Nice weather?\n\nType a number to choose, or 'bye' to stop talking.\n"
+	if output := buf.String(); output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+	unlocked.Triggered = true
+	if len(npc.AvailableTopics()) != 2 {
+		t.Errorf("Expected topic to unlock once its event is triggered")
+	}
+}
+
+func TestAskAboutTopic(t *testing.T) {
+	//Arrange
+	room := entities.Room{Entities: make(map[string]*entities.Entity)}
+	npc := entities.Entity{Name: "npc", Topics: []*entities.Topic{{Keyword: "weather", Prompt: "Nice weather?", Response: "Lovely."}}}
+	room.Entities[npc.Name] = &npc
+	player := entities.Player{CurrentRoom: &room}
+
+	//Act
+	known := player.Ask("npc", "weather")
+	unknown := player.Ask("npc", "politics")
+
+	//Assert
+	if !known {
+		t.Errorf("Expected asking about a known topic to succeed")
+	}
+	if unknown {
+		t.Errorf("Expected asking about an unknown topic to fail")
+	}
+}
+
+func TestGiveItem(t *testing.T) {
+	//Arrange
+	setUpValidInteractions()
+	room := entities.Room{Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	key := entities.Item{Name: "key", Weight: 1}
+	door := entities.Entity{Name: "door"}
+	room.Entities[door.Name] = &door
+	player := entities.Player{CurrentRoom: &room, Inventory: map[string]*entities.Item{key.Name: &key}}
+
+	//Act
+	player.Give("key", "door")
+
+	//Assert
+	if !entities.Index.HasTriggered("unlock_door") {
+		t.Errorf("Expected event to be true for triggered, got false")
+	}
+	if _, ok := player.Inventory["key"]; ok {
+		t.Errorf("Expected given item to have been removed from inventory")
+	}
+}
+
+func TestGiveItemForReward(t *testing.T) {
+	//Arrange
+	entities.Index = entities.NewRegistry(
+		&entities.Interaction{ItemName: "tea", EntityName: "npc", Event: &entities.Event{ID: "thirst_quenched"}, Reward: "badge"},
+	)
+	room := entities.Room{Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	tea := entities.Item{Name: "tea", Weight: 2}
+	badge := entities.Item{Name: "badge", Weight: 1}
+	npc := entities.Entity{Name: "npc", Inventory: map[string]*entities.Item{badge.Name: &badge}}
+	room.Entities[npc.Name] = &npc
+	player := entities.Player{CurrentRoom: &room, Inventory: map[string]*entities.Item{tea.Name: &tea}, CarriedWeight: 2, AvailableWeight: 8}
+
+	//Act
+	player.Give("tea", "npc")
+
+	//Assert
+	if _, ok := npc.Inventory["tea"]; !ok {
+		t.Errorf("Expected npc to hold the given item")
+	}
+	if _, ok := player.Inventory["badge"]; !ok {
+		t.Errorf("Expected player to receive the reward")
+	}
+	if player.AvailableWeight != 9 {
+		t.Errorf("Expected available weight to be 9, got %d", player.AvailableWeight)
+	}
+}
+
+func TestGiveItemNPCReaction(t *testing.T) {
+	//Arrange
+	setUpValidInteractions()
+	room := entities.Room{Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	cd := entities.Item{Name: "cd", Weight: 1}
+	book := entities.Item{Name: "book", Weight: 1}
+	npc := entities.Entity{Name: "npc", Inventory: make(map[string]*entities.Item), Reactions: map[string]string{"cd": "Thanks!"}}
+	room.Entities[npc.Name] = &npc
+	player := entities.Player{CurrentRoom: &room, Inventory: map[string]*entities.Item{cd.Name: &cd, book.Name: &book}}
+
+	//Act
+	accepted := player.Give("cd", "npc")
+	refused := player.Give("book", "npc")
+
+	//Assert
+	if !accepted || refused {
+		t.Errorf("Expected npc to accept only items it reacts to")
+	}
+	if _, ok := npc.Inventory["cd"]; !ok {
+		t.Errorf("Expected npc to hold the accepted item")
+	}
+	if _, ok := player.Inventory["book"]; !ok {
+		t.Errorf("Expected player to keep the refused item")
+	}
+}
+
+func TestShowRoomEntityHoldingItems(t *testing.T) {
+	// Arrange
+	room := entities.Room{Name: "Room 1", Description: "This is room 1.", Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	badge := entities.Item{Name: "badge"}
+	entity := entities.Entity{Name: "Entity", Description: "This is Entity", Inventory: map[string]*entities.Item{badge.Name: &badge}}
+	room.Entities[entity.Name] = &entity
+
+	player := entities.Player{CurrentRoom: &room}
+
+	r, w, _ := os.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	original := os.Stdout
+	os.Stdout = w
+
+	// Act
+	player.ShowRoom()
+
+	w.Close()
+	os.Stdout = original
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	// Assert
+	output := buf.String()
+	expectedOutput := "You are in Room 1\n\nThis is room 1.\n\nYou can approach:\n- Entity (holding badge)\n"
+
+	if output != expectedOutput {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expectedOutput, output)
+	}
+}
+
+func TestRoutineMovesEntity(t *testing.T) {
+	//Arrange
+	room1 := entities.Room{Name: "Room 1", Entities: make(map[string]*entities.Entity)}
+	room2 := entities.Room{Name: "Room 2", Entities: make(map[string]*entities.Entity)}
+	npc := entities.Entity{Name: "npc"}
+	room1.Entities[npc.Name] = &npc
+	routine := entities.Routine{Entity: &npc, Room: &room1, Route: []*entities.Room{&room1, &room2}, Every: 2}
+	player := entities.Player{CurrentRoom: &room1, CurrentEntity: &npc}
+
+	//Act
+	routine.Tick(&player)
+	stayed := room1.Entities[npc.Name] != nil
+	routine.Tick(&player)
+
+	//Assert
+	if !stayed {
+		t.Errorf("Expected npc to stay put before its turn")
+	}
+	if _, ok := room1.Entities[npc.Name]; ok {
+		t.Errorf("Expected npc to have left Room 1")
+	}
+	if _, ok := room2.Entities[npc.Name]; !ok {
+		t.Errorf("Expected npc to have arrived in Room 2")
+	}
+	if player.CurrentEntity != nil {
+		t.Errorf("Expected player to stop approaching an npc that left")
+	}
+}
+
+func TestRoutineReturnsToStartAfterRepeatedRoom(t *testing.T) {
+	//Arrange
+	home := entities.Room{Name: "home", Entities: make(map[string]*entities.Entity)}
+	hall := entities.Room{Name: "hall", Entities: make(map[string]*entities.Entity)}
+	study := entities.Room{Name: "study", Entities: make(map[string]*entities.Entity)}
+	npc := entities.Entity{Name: "npc"}
+	routine := entities.Routine{Entity: &npc, Route: []*entities.Room{&home, &hall, &study, &hall}, Every: 1}
+	player := entities.Player{CurrentRoom: &entities.Room{Entities: make(map[string]*entities.Entity)}}
+
+	//Act
+	visited := []string{}
+	for i := 0; i < 5; i++ {
+		routine.Tick(&player)
+		visited = append(visited, routine.Room.Name)
+	}
+
+	//Assert
+	expected := "home hall study hall home"
+	if strings.Join(visited, " ") != expected {
+		t.Errorf("Expected route %q, got %q", expected, strings.Join(visited, " "))
+	}
+}
+
+func TestRoutineWaitsUntilActive(t *testing.T) {
+	//Arrange
+	room := entities.Room{Name: "Room", Entities: make(map[string]*entities.Entity)}
+	npc := entities.Entity{Name: "npc"}
+	arrived := &entities.Event{ID: "arrival"}
+	routine := entities.Routine{Entity: &npc, Route: []*entities.Room{&room}, Active: func() bool { return arrived.Triggered }}
+	player := entities.Player{CurrentRoom: &room}
+
+	//Act
+	routine.Tick(&player)
+	early := room.Entities[npc.Name] != nil
+	arrived.Triggered = true
+	routine.Tick(&player)
+
+	//Assert
+	if early {
+		t.Errorf("Expected npc not to arrive before its routine is active")
+	}
+	if _, ok := room.Entities[npc.Name]; !ok {
+		t.Errorf("Expected npc to arrive once its routine is active")
+	}
+}
+
+func TestTakeContrabandWitnessed(t *testing.T) {
+	//Arrange
+	caught := &entities.Event{ID: "caught", Outcome: "You have been caught."}
+	room := entities.Room{Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	item := entities.Item{Name: "Item", Contraband: true, CaughtEvent: caught}
+	guard := entities.Entity{Name: "guard", Watchful: true}
+	room.Items[item.Name] = &item
+	room.Entities[guard.Name] = &guard
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item), AvailableWeight: 30}
+
+	//Act
+	player.Take(item.Name)
+
+	//Assert
+	if !caught.Triggered {
+		t.Errorf("Expected taking contraband in front of a watchful entity to be caught")
+	}
+}
+
+func TestTakeContrabandUnwitnessed(t *testing.T) {
+	//Arrange
+	caught := &entities.Event{ID: "caught", Outcome: "You have been caught."}
+	room := entities.Room{Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+	item := entities.Item{Name: "Item", Contraband: true, CaughtEvent: caught}
+	sleeper := entities.Entity{Name: "sleeper", Watchful: true, Asleep: true}
+	guard := entities.Entity{Name: "guard", Watchful: true}
+	guard.Distract(1)
+	room.Items[item.Name] = &item
+	room.Entities[sleeper.Name] = &sleeper
+	room.Entities[guard.Name] = &guard
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item), AvailableWeight: 30}
+
+	//Act
+	player.Take(item.Name)
+	guard.PassTurn()
+
+	//Assert
+	if caught.Triggered {
+		t.Errorf("Expected contraband taken while nobody is watching to go unnoticed")
+	}
+	if player.Witness() != &guard {
+		t.Errorf("Expected guard to notice again once the distraction has passed")
+	}
+}
+
+func TestMoodLabels(t *testing.T) {
+	//Arrange
+	npc := entities.Entity{Name: "npc", Mood: &entities.Mood{Value: 50, GrumpyAt: 0, AnnoyedAt: 25, CheerfulAt: 70}}
+
+	//Act
+	calm := npc.MoodLabel()
+	npc.ChangeMood(30)
+	cheerful := npc.MoodLabel()
+	npc.ChangeMood(-60)
+	annoyed := npc.MoodLabel()
+	npc.ChangeMood(-20)
+
+	//Assert
+	if calm != "calm" || cheerful != "cheerful" || annoyed != "annoyed" {
+		t.Errorf("Expected calm, cheerful and annoyed, got %s, %s and %s", calm, cheerful, annoyed)
+	}
+	if !npc.IsGrumpy() {
+		t.Errorf("Expected npc to be grumpy once the threshold is crossed")
+	}
+}
+
+func TestChangeMoodUsesDisplayName(t *testing.T) {
+	//Arrange
+	npc := entities.Entity{Name: "rosie", Mood: &entities.Mood{Value: 50, GrumpyAt: 0, AnnoyedAt: 25, CheerfulAt: 70}}
+
+	r, w, _ := os.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	original := os.Stdout
+	os.Stdout = w
+
+	//Act
+	npc.ChangeMood(-5)
+
+	w.Close()
+	os.Stdout = original
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	//Assert
+	expected := "Rosie looks annoyed with you.\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestUseItemChangesMood(t *testing.T) {
+	//Arrange
+	entities.Index = entities.NewRegistry(
+		&entities.Interaction{ItemName: "tea", EntityName: "npc", Event: &entities.Event{ID: "thirst_quenched"}, Mood: 30},
+	)
+	room := entities.Room{Entities: make(map[string]*entities.Entity)}
+	tea := entities.Item{Name: "tea"}
+	npc := entities.Entity{Name: "npc", Mood: &entities.Mood{Value: 10, GrumpyAt: 0, AnnoyedAt: 25, CheerfulAt: 70}, Topics: []*entities.Topic{
+		{Keyword: "sorry", Prompt: "Sorry?", Response: "Hmph.", Mood: "annoyed"},
+	}}
+	room.Entities[npc.Name] = &npc
+	player := entities.Player{CurrentRoom: &room, Inventory: map[string]*entities.Item{tea.Name: &tea}}
+	topicsBefore := len(npc.AvailableTopics())
+
+	//Act
+	player.Give("tea", "npc")
+
+	//Assert
+	if npc.Mood.Value != 40 {
+		t.Errorf("Expected mood to be 40, got %d", npc.Mood.Value)
+	}
+	if topicsBefore != 1 || len(npc.AvailableTopics()) != 0 {
+		t.Errorf("Expected mood-specific topic to disappear once the mood changes")
+	}
+}
+
+func TestAdvanceClock(t *testing.T) {
+	//Arrange
+	globalGame.CurrentTime = globalGame.StartTime
+	globalGame.Turn = 0
+
+	//Act
+	globalGame.AdvanceClock("move")
+	globalGame.AdvanceClock("inventory")
+	globalGame.AdvanceClock("dance")
+
+	//Assert
+	if globalGame.CurrentTime != globalGame.StartTime+5+globalGame.DefaultVerbCost {
+		t.Errorf("Expected clock to advance by the cost of each verb, got %s", globalGame.FormatTime(globalGame.CurrentTime))
+	}
+	if globalGame.Turn != 2 {
+		t.Errorf("Expected free commands not to count as turns, got %d turns", globalGame.Turn)
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	//Arrange
+	globalGame.CurrentTime = 15*60 + 5
+	globalGame.Turn = 12
+
+	//Act
+	status := globalGame.StatusLine()
+
+	//Assert
+	expected := "Time: 3:05pm | Turn: 12 | Deadline: 4:00pm"
+	if status != expected {
+		t.Errorf("Expected status line:\n%s\nGot:\n%s", expected, status)
+	}
+}
+
+func TestTriggerTimedEvents(t *testing.T) {
+	//Arrange
+	early := &entities.Event{ID: "early", Outcome: "Early."}
+	late := &entities.Event{ID: "late", Outcome: "Late."}
+	timedEvents := []*entities.TimedEvent{{At: 60, Event: early}, {At: 120, Event: late}}
+	player := entities.Player{}
+
+	//Act
+	player.TriggerTimedEvents(timedEvents, 90)
+
+	//Assert
+	if !early.Triggered || late.Triggered {
+		t.Errorf("Expected only events scheduled before the current time to fire")
+	}
+}
+
+func TestProcessCompletesAfterTurns(t *testing.T) {
+	//Arrange
+	done := &entities.Event{ID: "boiled", Outcome: "The kettle clicks off."}
+	room := entities.Room{Entities: make(map[string]*entities.Entity)}
+	kettle := entities.Entity{Name: "kettle"}
+	kettle.Process = &entities.Process{Entity: &kettle, Turns: 2, Done: done}
+	room.Entities[kettle.Name] = &kettle
+	player := entities.Player{CurrentRoom: &room}
+
+	//Act
+	started := kettle.Process.Start()
+	restarted := kettle.Process.Start()
+	kettle.Process.Tick(&player)
+	midway := done.Triggered
+	kettle.Process.Tick(&player)
+
+	//Assert
+	if !started || restarted {
+		t.Errorf("Expected a process to start only once")
+	}
+	if midway {
+		t.Errorf("Expected process not to complete before its turns are up")
+	}
+	if !done.Triggered || kettle.Process.Running {
+		t.Errorf("Expected process to complete and stop running")
+	}
+}
+
+func TestProcessIdleUntilStarted(t *testing.T) {
+	//Arrange
+	done := &entities.Event{ID: "washed"}
+	dishwasher := entities.Entity{Name: "dishwasher"}
+	process := entities.Process{Entity: &dishwasher, Turns: 1, Done: done}
+	player := entities.Player{CurrentRoom: &entities.Room{Entities: make(map[string]*entities.Entity)}}
+
+	//Act
+	entities.TickProcesses([]*entities.Process{&process}, &player)
+
+	//Assert
+	if done.Triggered {
+		t.Errorf("Expected a process that was never started not to complete")
+	}
+}
+
+func TestDrinkUsesChargesUntilSpent(t *testing.T) {
+	//Arrange
+	boost := &entities.Event{ID: "tea-drunk"}
+	tea := entities.Item{Name: "tea", Weight: 2, State: "hot", Charges: 2, SpentState: "empty", Actions: map[string]*entities.ItemAction{
+		"drink": {Message: "Sip.", UsesCharge: true, Event: boost},
+	}}
+	player := entities.Player{Inventory: map[string]*entities.Item{tea.Name: &tea}, CarriedWeight: 2}
+
+	//Act
+	first := player.Act("drink", "tea")
+	second := player.Act("drink", "tea")
+	third := player.Act("drink", "tea")
+
+	//Assert
+	if !first || !second || third {
+		t.Errorf("Expected two sips to succeed and the third to fail")
+	}
+	if !boost.Triggered {
+		t.Errorf("Expected drinking to trigger its event")
+	}
+	if tea.GetState() != "empty" || player.Inventory["tea"] == nil {
+		t.Errorf("Expected the mug to stay in the inventory in its spent state, got %s", tea.GetState())
+	}
+}
+
+func TestActConsumesItemWithoutSpentState(t *testing.T) {
+	//Arrange
+	biscuit := entities.Item{Name: "biscuit", Weight: 1, Charges: 1, Actions: map[string]*entities.ItemAction{
+		"eat": {Message: "Crunch.", UsesCharge: true},
+	}}
+	player := entities.Player{Inventory: map[string]*entities.Item{biscuit.Name: &biscuit}, CarriedWeight: 1}
+
+	//Act
+	player.Act("eat", "biscuit")
+
+	//Assert
+	if _, ok := player.Inventory["biscuit"]; ok || player.CarriedWeight != 0 {
+		t.Errorf("Expected a used-up item to leave the inventory")
+	}
+}
+
+func TestUseRequiresItemState(t *testing.T) {
+	//Arrange
+	entities.Index = entities.NewRegistry(
+		&entities.Interaction{ItemName: "tea", EntityName: "npc", Event: &entities.Event{ID: "thirst_quenched"}, ItemState: "hot"},
+	)
+	room := entities.Room{Entities: make(map[string]*entities.Entity)}
+	tea := entities.Item{Name: "tea", State: "cold"}
+	npc := entities.Entity{Name: "npc"}
+	room.Entities[npc.Name] = &npc
+	player := entities.Player{CurrentRoom: &room, CurrentEntity: &npc, Inventory: map[string]*entities.Item{tea.Name: &tea}}
+
+	//Act
+	refused := player.Use("tea", "npc")
+	tea.SetState("hot")
+	accepted := player.Use("tea", "npc")
+
+	//Assert
+	if refused || !accepted {
+		t.Errorf("Expected cold tea to be refused and hot tea to be accepted")
+	}
+}
+
+func TestCombineItems(t *testing.T) {
+	//Arrange
+	entities.ValidRecipes = []*entities.Recipe{
+		{Inputs: []entities.Ingredient{{Name: "handle", Consumed: true}, {Name: "blade", Consumed: false}}, Output: &entities.Item{Name: "knife", Weight: 1}, Message: "Done."},
+	}
+	handle := entities.Item{Name: "handle", Weight: 1}
+	blade := entities.Item{Name: "blade", Weight: 1}
+	player := entities.Player{CurrentRoom: &entities.Room{Items: make(map[string]*entities.Item)}, Inventory: map[string]*entities.Item{handle.Name: &handle, blade.Name: &blade}, CarriedWeight: 2, AvailableWeight: 8}
+
+	//Act
+	ok := player.Combine("blade", "handle")
+
+	//Assert
+	if !ok {
+		t.Fatalf("Expected the items to combine")
+	}
+	if _, hasHandle := player.Inventory["handle"]; hasHandle {
+		t.Errorf("Expected the consumed input to be removed")
+	}
+	if _, hasBlade := player.Inventory["blade"]; !hasBlade {
+		t.Errorf("Expected the input that is not consumed to be kept")
+	}
+	if _, hasKnife := player.Inventory["knife"]; !hasKnife || player.CarriedWeight != 2 {
+		t.Errorf("Expected the output in the inventory with weight carried %d, got %d", 2, player.CarriedWeight)
+	}
+}
+
+func TestCombineRefusesOutputAlreadyHeld(t *testing.T) {
+	//Arrange
+	knife := &entities.Item{Name: "knife", Weight: 1}
+	entities.ValidRecipes = []*entities.Recipe{
+		{Inputs: []entities.Ingredient{{Name: "whetstone", Consumed: false}, {Name: "blade", Consumed: false}}, Output: knife, Message: "Done."},
+	}
+	whetstone := entities.Item{Name: "whetstone", Weight: 1}
+	blade := entities.Item{Name: "blade", Weight: 1}
+	player := entities.Player{CurrentRoom: &entities.Room{Items: make(map[string]*entities.Item)}, Inventory: map[string]*entities.Item{whetstone.Name: &whetstone, blade.Name: &blade}, CarriedWeight: 2, AvailableWeight: 8}
+
+	//Act
+	first := player.Combine("whetstone", "blade")
+	second := player.Combine("whetstone", "blade")
+
+	//Assert
+	if !first || second {
+		t.Errorf("Expected the output to be crafted only once")
+	}
+	if player.CarriedWeight != 3 {
+		t.Errorf("Expected weight carried %d, got %d", 3, player.CarriedWeight)
+	}
+}
+
+func TestCombineWithoutRecipe(t *testing.T) {
+	//Arrange
+	entities.ValidRecipes = []*entities.Recipe{}
+	tea := entities.Item{Name: "tea"}
+	cd := entities.Item{Name: "cd"}
+	player := entities.Player{Inventory: map[string]*entities.Item{tea.Name: &tea, cd.Name: &cd}}
+
+	r, w, _ := os.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	original := os.Stdout
+	os.Stdout = w
+
+	//Act
+	player.Combine("tea", "cd")
+
+	w.Close()
+	os.Stdout = original
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	//Assert
+	output := buf.String()
+	expected := "You can't combine tea with cd.\n"
+	if output != expected {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expected, output)
+	}
+}
+
+func TestValidateWorld(t *testing.T) {
+	//Arrange
+	entities.Index = entities.NewRegistry(
+		&entities.Interaction{ItemName: "tea", EntityName: "ghost", Event: &entities.Event{ID: "haunted"}},
+	)
+	entities.ValidRecipes = []*entities.Recipe{
+		{Inputs: []entities.Ingredient{{Name: "tea"}, {Name: "sugar"}}, Output: &entities.Item{Name: "sweet-tea"}},
+	}
+	elsewhere := entities.Room{Name: "elsewhere"}
+	room := entities.Room{Name: "room", Items: map[string]*entities.Item{"tea": {Name: "tea"}}, Entities: make(map[string]*entities.Entity), Exits: map[string]*entities.Room{"north": &elsewhere}}
+
+	//Act
+	problems := entities.ValidateWorld([]*entities.Room{&room}, nil)
+
+	//Assert
+	if len(problems) != 3 {
+		t.Errorf("Expected a bad exit, an unknown entity and an unknown recipe input, got %v", problems)
+	}
+}
+
+func TestPerformVerbInteraction(t *testing.T) {
+	//Arrange
+	purr := &entities.Event{ID: "cat-petted", Outcome: "Purr."}
+	entities.Index = entities.NewRegistry(
+		&entities.Interaction{Verb: "pet", EntityName: "cat", Event: purr},
+	)
+	room := entities.Room{Entities: make(map[string]*entities.Entity)}
+	cat := entities.Entity{Name: "cat", Aliases: []string{"feline"}}
+	room.Entities[cat.Name] = &cat
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item)}
+
+	published := 0
+	entities.Happenings = entities.NewBus()
+	entities.Happenings.Subscribe(entities.EventTriggered, func(entities.Happening) { published++ })
+
+	//Act
+	petted := player.Perform("pet", "feline")
+	pettedAgain := player.Perform("pet", "cat")
+	pushed := player.Perform("push", "cat")
+
+	//Assert
+	if !petted || !purr.Triggered {
+		t.Errorf("Expected petting the cat to trigger its event")
+	}
+	if pettedAgain || published != 1 {
+		t.Errorf("Expected petting the cat again to publish nothing, got %d events", published)
+	}
+	if pushed {
+		t.Errorf("Expected a verb without an interaction to fail")
+	}
+	if !entities.Index.HasVerb("pet") || entities.Index.HasVerb("use") {
+		t.Errorf("Expected only verbs defined in interactions to be recognised")
+	}
+}
+
+func TestPerformRequiresItem(t *testing.T) {
+	//Arrange
+	opened := &entities.Event{ID: "door-opened"}
+	entities.Index = entities.NewRegistry(
+		&entities.Interaction{Verb: "open", ItemName: "key", EntityName: "door", Event: opened},
+	)
+	room := entities.Room{Entities: make(map[string]*entities.Entity)}
+	door := entities.Entity{Name: "door"}
+	room.Entities[door.Name] = &door
+	key := entities.Item{Name: "key"}
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item)}
+
+	//Act
+	withoutKey := player.Perform("open", "door")
+	player.Inventory[key.Name] = &key
+	withKey := player.Perform("open", "door")
+
+	//Assert
+	if withoutKey || !withKey || !opened.Triggered {
+		t.Errorf("Expected the door to open only once the key is carried")
+	}
+}
+
+func TestPerformChecksRequirementBeforeTriggering(t *testing.T) {
+	//Arrange
+	ready := false
+	started := &entities.Event{ID: "machine-started"}
+	entities.Index = entities.NewRegistry(
+		&entities.Interaction{Verb: "start", EntityName: "machine", Event: started, Requires: func() bool { return ready }, Refusal: "Not yet."},
+	)
+	room := entities.Room{Entities: make(map[string]*entities.Entity)}
+	machine := entities.Entity{Name: "machine"}
+	room.Entities[machine.Name] = &machine
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item)}
+	published := 0
+	entities.Happenings = entities.NewBus()
+	entities.Happenings.Subscribe(entities.EventTriggered, func(entities.Happening) { published++ })
+
+	//Act
+	early := player.Perform("start", "machine")
+	ready = true
+	late := player.Perform("start", "machine")
+
+	//Assert
+	if early || !late || !started.Triggered || published != 1 {
+		t.Errorf("Expected the event to fire only once its requirement holds, got %d events", published)
+	}
+}
+
+func TestRegistryIndexesInteractionsAndEvents(t *testing.T) {
+	//Arrange
+	registry := entities.NewRegistry()
+	for i := 0; i < 5000; i++ {
+		registry.Add(&entities.Interaction{ItemName: fmt.Sprintf("item-%d", i), EntityName: "machine", Event: &entities.Event{ID: fmt.Sprintf("event-%d", i)}})
+	}
+	alarm := &entities.Event{ID: "alarm", Triggered: true}
+	registry.RegisterEvent(alarm)
+
+	//Act
+	interaction, found := registry.FindUse("item-4321", "machine")
+	_, missing := registry.FindUse("item-4321", "toaster")
+
+	//Assert
+	if !found || interaction.Event.ID != "event-4321" || missing {
+		t.Errorf("Expected to find the interaction by item and entity only")
+	}
+	if !registry.HasTriggered("alarm") || registry.HasTriggered("event-4321") || registry.HasTriggered("unknown") {
+		t.Errorf("Expected only triggered, registered events to report as triggered")
+	}
+	if len(registry.Interactions()) != 5000 {
+		t.Errorf("Expected 5000 interactions, got %d", len(registry.Interactions()))
+	}
+}
+
+func TestBusPublishesToSubscribersOfKind(t *testing.T) {
+	//Arrange
+	bus := entities.NewBus()
+	taken := []string{}
+	dropped := 0
+	bus.Subscribe(entities.ItemTaken, func(happening entities.Happening) {
+		taken = append(taken, happening.Item.Name)
+	})
+	bus.Subscribe(entities.ItemDropped, func(happening entities.Happening) {
+		dropped++
+	})
+
+	//Act
+	bus.Publish(entities.Happening{Kind: entities.ItemTaken, Item: &entities.Item{Name: "tea"}})
+	bus.Publish(entities.Happening{Kind: entities.RoomEntered})
+
+	//Assert
+	if len(taken) != 1 || taken[0] != "tea" || dropped != 0 {
+		t.Errorf("Expected only the item-taken subscriber to hear about tea, got %v and %d drops", taken, dropped)
+	}
+}
+
+func TestPlayerActionsPublishHappenings(t *testing.T) {
+	//Arrange
+	entities.Happenings = entities.NewBus()
+	kinds := []entities.HappeningKind{}
+	record := func(happening entities.Happening) {
+		kinds = append(kinds, happening.Kind)
+	}
+	for _, kind := range []entities.HappeningKind{entities.ItemTaken, entities.ItemDropped, entities.RoomEntered, entities.EntityApproached, entities.EventTriggered} {
+		entities.Happenings.Subscribe(kind, record)
+	}
+	hallway := entities.Room{Name: "hallway", Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity), Exits: make(map[string]*entities.Room)}
+	room := entities.Room{Name: "room", Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity), Exits: map[string]*entities.Room{"north": &hallway}}
+	room.Items["key"] = &entities.Item{Name: "key", Weight: 1}
+	room.Entities["door"] = &entities.Entity{Name: "door"}
+	player := entities.Player{CurrentRoom: &room, Inventory: make(map[string]*entities.Item), AvailableWeight: 10}
+
+	//Act
+	player.Take("key")
+	player.Approach("door")
+	player.TriggerEvent(&entities.Event{ID: "knocked"})
+	player.Drop("key")
+	player.Move("north")
+	entities.Happenings = entities.NewBus()
+
+	//Assert
+	expected := []entities.HappeningKind{entities.ItemTaken, entities.EntityApproached, entities.EventTriggered, entities.ItemDropped, entities.RoomEntered}
+	if fmt.Sprint(kinds) != fmt.Sprint(expected) {
+		t.Errorf("Expected happenings %v, got %v", expected, kinds)
+	}
+}
+
+func TestVariablesStoreAndDump(t *testing.T) {
+	//Arrange
+	vars := globalGame.NewVariables()
+
+	//Act
+	vars.SetBool("door-open", true)
+	vars.SetInt("attempts", 3)
+	vars.AddInt("attempts", -1)
+	vars.SetString("mood", "grumpy")
+
+	//Assert
+	if !vars.Bool("door-open") || vars.Int("attempts") != 2 || vars.String("mood") != "grumpy" {
+		t.Errorf("Expected stored values to be read back")
+	}
+	if _, ok := vars.Lookup("missing"); ok {
+		t.Errorf("Expected an unset variable not to be found")
+	}
+	expected := "attempts = 2\ndoor-open = true\nmood = \"grumpy\""
+	if vars.Dump() != expected {
+		t.Errorf("Expected dump:\n%s\nGot:\n%s", expected, vars.Dump())
+	}
+}
+
+func TestTemplatedDescriptionRendersAtDisplayTime(t *testing.T) {
+	//Arrange
+	opened := &entities.Event{ID: "door-opened"}
+	entities.Index = entities.NewRegistry()
+	entities.Index.RegisterEvent(opened)
+	globalGame.Vars = globalGame.NewVariables()
+	globalGame.Vars.SetInt("knocks", 2)
+	door := entities.Entity{Name: "door", Description: "{{if triggered \"door-opened\"}}The door stands open.{{else}}A locked door. Knocks: {{var \"knocks\"}}.{{end}}"}
+
+	//Act
+	before := door.GetDescription()
+	opened.Triggered = true
+	after := door.GetDescription()
+
+	//Assert
+	if before != "A locked door. Knocks: 2." {
+		t.Errorf("Expected the locked description with the knock count, got %q", before)
+	}
+	if after != "The door stands open." {
+		t.Errorf("Expected the description to follow the triggered event, got %q", after)
+	}
+}
+
+func TestTemplateReportsUnknownVariable(t *testing.T) {
+	//Arrange
+	globalGame.Vars = globalGame.NewVariables()
+	globalGame.Debug = true
+	defer func() { globalGame.Debug = false }()
+	text := "Knocks: {{var \"missing\"}}."
+
+	r, w, _ := os.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	original := os.Stdout
+	os.Stdout = w
+
+	//Act
+	rendered := entities.Render(text)
+
+	w.Close()
+	os.Stdout = original
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	//Assert
+	if strings.Contains(rendered, "<no value>") {
+		t.Errorf("Expected an unknown variable not to render as <no value>, got %q", rendered)
+	}
+	if !strings.Contains(buf.String(), "unknown variable \"missing\"") {
+		t.Errorf("Expected the unknown variable to be reported, got %q", buf.String())
+	}
+}
+
+func TestValidateWorldReportsBadTemplates(t *testing.T) {
+	//Arrange
+	entities.Index = entities.NewRegistry()
+	entities.ValidRecipes = []*entities.Recipe{}
+	room := entities.Room{Name: "room", Description: "{{if triggered \"x\"}}unfinished", Items: make(map[string]*entities.Item), Entities: make(map[string]*entities.Entity)}
+
+	//Act
+	problems := entities.ValidateWorld([]*entities.Room{&room}, nil)
+
+	//Assert
+	if len(problems) != 1 {
+		t.Errorf("Expected one template problem, got %v", problems)
+	}
+}
+
+func TestEntityStateMachineTransitions(t *testing.T) {
+	//Arrange
+	entered := 0
+	safe := entities.Entity{Name: "safe", State: "locked", Machine: &entities.StateMachine{
+		States: map[string]*entities.MachineState{
+			"locked": {Description: "A locked safe."},
+			"open":   {Description: "An open safe.", LongDescription: "The safe door hangs open.", OnEnter: func() { entered++ }},
+		},
+		Transitions: map[string][]string{"locked": {"open"}},
+	}}
+
+	//Act
+	lockedDescription := safe.GetDescription()
+	opened := safe.Transition("open")
+	again := safe.Transition("open")
+	relocked := safe.Transition("locked")
+
+	//Assert
+	if lockedDescription != "A locked safe." || safe.GetDescription() != "An open safe." || safe.GetLongDescription() != "The safe door hangs open." {
+		t.Errorf("Expected descriptions to follow the current state")
+	}
+	if opened != nil || again == nil || entered != 1 {
+		t.Errorf("Expected one successful transition with one on-enter effect, got %v, %v and %d", opened, again, entered)
+	}
+	if relocked == nil || safe.GetState() != "open" {
+		t.Errorf("Expected a transition that is not allowed to fail")
+	}
+	if entities.DescribeMachine(&safe) != "safe: open (final)" {
+		t.Errorf("Expected a final state, got %s", entities.DescribeMachine(&safe))
+	}
+}
+
+func TestScoringAwardsMilestonesOnceAndPenalties(t *testing.T) {
+	//Arrange
+	scoring := entities.NewScoring(map[string]entities.ScoreRule{
+		"first-plate-loaded":  {Label: "Plate loaded", Points: 5},
+		"second-plate-loaded": {Label: "Plate loaded", Points: 5},
+		"escaped":             {Label: "Escaped", Points: 50},
+	}, map[string]entities.ScoreRule{
+		"wrong-password": {Label: "Wrong password", Points: -2},
+	})
+
+	//Act
+	scoring.Record("first-plate-loaded")
+	scoring.Record("first-plate-loaded")
+	scoring.Record("second-plate-loaded")
+	scoring.Record("unscored")
+	scoring.Penalise("wrong-password")
+	scoring.Penalise("wrong-password")
+
+	//Assert
+	if scoring.Total() != 6 {
+		t.Errorf("Expected a total of 6, got %d", scoring.Total())
+	}
+	expected := "- Plate loaded x2: +10\n- Wrong password x2: -4"
+	if scoring.Breakdown() != expected {
+		t.Errorf("Expected breakdown:\n%s\nGot:\n%s", expected, scoring.Breakdown())
+	}
+}
+
+func TestTrackAchievementsUnlocksWhenConditionHolds(t *testing.T) {
+	//Arrange
+	bus := entities.NewBus()
+	wrongPasswords := 1
+	unlocked := []string{}
+	achievements := []*entities.Achievement{
+		{ID: "flawless", Title: "Flawless", Event: "escaped", Condition: func() bool { return wrongPasswords == 0 }},
+		{ID: "cat-person", Title: "Cat Person", Event: "cat-petted"},
+	}
+	entities.TrackAchievements(bus, achievements, func(achievement *entities.Achievement) bool {
+		unlocked = append(unlocked, achievement.ID)
+		return true
+	})
+
+	//Act
+	bus.Publish(entities.Happening{Kind: entities.EventTriggered, Event: &entities.Event{ID: "escaped"}})
+	bus.Publish(entities.Happening{Kind: entities.EventTriggered, Event: &entities.Event{ID: "cat-petted"}})
+
+	//Assert
+	if len(unlocked) != 1 || unlocked[0] != "cat-person" {
+		t.Errorf("Expected only the cat achievement to unlock, got %v", unlocked)
+	}
+}
+
+func TestProfileUnlocksAchievementOnce(t *testing.T) {
+	//Arrange
+	playerProfile := profile.Profile{Name: "test"}
+
+	//Act
+	first := playerProfile.Unlock("cat-person")
+	second := playerProfile.Unlock("cat-person")
+
+	//Assert
+	if !first || second || !playerProfile.HasAchievement("cat-person") {
+		t.Errorf("Expected an achievement to unlock only once")
+	}
+}
+
+func TestEndingsRecordFirstEndingReached(t *testing.T) {
+	//Arrange
+	bus := entities.NewBus()
+	endings := entities.NewEndings(
+		&entities.Ending{ID: "escaped", Title: "Escaped", Win: true, Event: "exits-unlocked"},
+		&entities.Ending{ID: "out-of-time", Title: "Out of time", Event: "deadline-missed"},
+	)
+	endings.Track(bus)
+	globalGame.GameOver = false
+
+	//Act
+	bus.Publish(entities.Happening{Kind: entities.EventTriggered, Event: &entities.Event{ID: "kettle-boiled"}})
+	stillPlaying := !globalGame.GameOver
+	bus.Publish(entities.Happening{Kind: entities.EventTriggered, Event: &entities.Event{ID: "deadline-missed"}})
+	bus.Publish(entities.Happening{Kind: entities.EventTriggered, Event: &entities.Event{ID: "exits-unlocked"}})
+	gameOver := globalGame.GameOver
+	globalGame.GameOver = false
+
+	//Assert
+	if !stillPlaying || !gameOver {
+		t.Errorf("Expected only an ending event to end the game")
+	}
+	if endings.Reached == nil || endings.Reached.ID != "out-of-time" {
+		t.Errorf("Expected the first ending reached to be kept, got %v", endings.Reached)
+	}
+}
+
+func TestProfileRemembersEndingsSeen(t *testing.T) {
+	//Arrange
+	playerProfile := profile.Profile{Name: "test"}
+
+	//Act
+	first := playerProfile.SeeEnding("escaped")
+	second := playerProfile.SeeEnding("escaped")
+
+	//Assert
+	if !first || second || !playerProfile.HasSeenEnding("escaped") || playerProfile.HasSeenEnding("out-of-time") {
+		t.Errorf("Expected each ending to be recorded once")
+	}
+}
+
+func TestHintsEscalateAndRespectBudget(t *testing.T) {
+	//Arrange
+	solved := false
+	hints := entities.NewHints(3,
+		&entities.HintStage{ID: "first", Applies: func() bool { return !solved }, Tiers: []string{"vague", "explicit"}},
+		&entities.HintStage{ID: "second", Applies: func() bool { return true }, Tiers: []string{"next"}},
+	)
+
+	//Act
+	first, _ := hints.Next()
+	second, _ := hints.Next()
+	solved = true
+	third, _ := hints.Next()
+	_, ok := hints.Next()
+
+	//Assert
+	if first != "vague" || second != "explicit" || third != "next" {
+		t.Errorf("Expected hints to escalate and follow progress, got %q, %q, %q", first, second, third)
+	}
+	if ok || hints.Remaining() != 0 {
+		t.Errorf("Expected no hints once the budget is spent")
+	}
+}
@@ -0,0 +1,131 @@
+package profile
+
+import (
+	"academy-adventure-game/globalGame"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Profile struct {
+	Name         string                `json:"name"`
+	Aliases      map[string]string     `json:"aliases"`
+	Achievements []string              `json:"achievements"`
+	EndingsSeen  []string              `json:"endings_seen"`
+	LastGame     *globalGame.Variables `json:"last_game,omitempty"`
+}
+
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "academy-adventure-game"), nil
+}
+
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	return nil
+}
+
+func Path(name string) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+func HistoryPath(name string) (string, error) {
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".history"), nil
+}
+
+func Load(name string) (*Profile, error) {
+	p := &Profile{Name: name, Aliases: make(map[string]string)}
+	path, err := Path(name)
+	if err != nil {
+		return p, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return p, err
+	}
+	if p.Aliases == nil {
+		p.Aliases = make(map[string]string)
+	}
+	return p, nil
+}
+
+func (p *Profile) Save() error {
+	path, err := Path(p.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (p *Profile) HasAchievement(id string) bool {
+	return contains(p.Achievements, id)
+}
+
+func (p *Profile) Unlock(id string) bool {
+	return addUnique(&p.Achievements, id)
+}
+
+func (p *Profile) HasSeenEnding(id string) bool {
+	return contains(p.EndingsSeen, id)
+}
+
+func (p *Profile) SeeEnding(id string) bool {
+	return addUnique(&p.EndingsSeen, id)
+}
+
+func contains(list []string, id string) bool {
+	for _, entry := range list {
+		if entry == id {
+			return true
+		}
+	}
+	return false
+}
+
+func addUnique(list *[]string, id string) bool {
+	if contains(*list, id) {
+		return false
+	}
+	*list = append(*list, id)
+	sort.Strings(*list)
+	return true
+}
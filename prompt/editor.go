@@ -0,0 +1,240 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const maxHistory = 500
+
+var ErrInterrupted = errors.New("interrupted")
+
+type Editor struct {
+	History     []string
+	HistoryPath string
+	Complete    func(line string) []string
+	Private     bool
+	reader      *bufio.Reader
+	out         io.Writer
+}
+
+func New(historyPath string, complete func(line string) []string) *Editor {
+	return &Editor{
+		History:     LoadHistory(historyPath),
+		HistoryPath: historyPath,
+		Complete:    complete,
+		reader:      bufio.NewReader(os.Stdin),
+		out:         os.Stdout,
+	}
+}
+
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := makeRaw(fd)
+	if err != nil {
+		fmt.Fprint(e.out, prompt)
+		line, err := e.reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	line, err := e.edit(prompt)
+	restore(fd, state)
+	fmt.Fprintln(e.out)
+	if err == nil && !e.Private {
+		e.remember(line)
+	}
+	return line, err
+}
+
+func (e *Editor) edit(prompt string) (string, error) {
+	buf := []rune{}
+	pos := 0
+	index := len(e.History)
+	draft := ""
+	e.refresh(prompt, buf, pos)
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			return string(buf), nil
+		case 3:
+			return "", ErrInterrupted
+		case 4:
+			if len(buf) == 0 {
+				return "", io.EOF
+			}
+		case 127, 8:
+			if pos > 0 {
+				buf = append(buf[:pos-1], buf[pos:]...)
+				pos--
+			}
+		case 1:
+			pos = 0
+		case 5:
+			pos = len(buf)
+		case 21:
+			buf = buf[pos:]
+			pos = 0
+		case '\t':
+			buf, pos = e.complete(prompt, buf, pos)
+		case 27:
+			if next, _, _ := e.reader.ReadRune(); next != '[' {
+				break
+			}
+			params, code := e.readSequence()
+			switch code {
+			case 'A':
+				if index > 0 {
+					if index == len(e.History) {
+						draft = string(buf)
+					}
+					index--
+					buf = []rune(e.History[index])
+					pos = len(buf)
+				}
+			case 'B':
+				if index < len(e.History) {
+					index++
+					if index == len(e.History) {
+						buf = []rune(draft)
+					} else {
+						buf = []rune(e.History[index])
+					}
+					pos = len(buf)
+				}
+			case 'C':
+				if pos < len(buf) {
+					pos++
+				}
+			case 'D':
+				if pos > 0 {
+					pos--
+				}
+			case 'H':
+				pos = 0
+			case 'F':
+				pos = len(buf)
+			case '~':
+				if params == "3" && pos < len(buf) {
+					buf = append(buf[:pos], buf[pos+1:]...)
+				}
+			}
+		default:
+			if r >= 32 {
+				buf = append(buf[:pos], append([]rune{r}, buf[pos:]...)...)
+				pos++
+			}
+		}
+		e.refresh(prompt, buf, pos)
+	}
+}
+
+func (e *Editor) readSequence() (string, rune) {
+	params := []rune{}
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return string(params), 0
+		}
+		if r >= 0x40 && r <= 0x7e {
+			return string(params), r
+		}
+		params = append(params, r)
+	}
+}
+
+func (e *Editor) refresh(prompt string, buf []rune, pos int) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(buf))
+	if back := len(buf) - pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func (e *Editor) complete(prompt string, buf []rune, pos int) ([]rune, int) {
+	if e.Complete == nil {
+		return buf, pos
+	}
+	start := pos
+	for start > 0 && !strings.ContainsRune(" ,;", buf[start-1]) {
+		start--
+	}
+	word := string(buf[start:pos])
+	candidates := []string{}
+	for _, candidate := range e.Complete(string(buf[:pos])) {
+		if strings.HasPrefix(candidate, word) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		fmt.Fprint(e.out, "\a")
+		return buf, pos
+	}
+	insert := CommonPrefix(candidates)[len(word):]
+	if len(candidates) == 1 {
+		insert += " "
+	} else if insert == "" {
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+		return buf, pos
+	}
+	buf = append(buf[:pos], append([]rune(insert), buf[pos:]...)...)
+	return buf, pos + len([]rune(insert))
+}
+
+func (e *Editor) remember(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || (len(e.History) > 0 && e.History[len(e.History)-1] == line) {
+		return
+	}
+	e.History = append(e.History, line)
+	if len(e.History) > maxHistory {
+		e.History = e.History[len(e.History)-maxHistory:]
+	}
+	if e.HistoryPath == "" {
+		return
+	}
+	file, err := os.OpenFile(e.HistoryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	fmt.Fprintln(file, line)
+}
+
+func LoadHistory(path string) []string {
+	history := []string{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return history
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return history
+}
+
+func CommonPrefix(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
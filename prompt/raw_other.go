@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package prompt
+
+import "errors"
+
+type termState struct{}
+
+func makeRaw(fd int) (*termState, error) {
+	return nil, errors.New("line editing is not supported on this platform")
+}
+
+func restore(fd int, state *termState) error {
+	return nil
+}